@@ -0,0 +1,92 @@
+/*
+antichess.go implements the rules of Antichess, also known as losing chess.
+Captures are compulsory, the king is an ordinary piece which may be captured,
+and there is no castling.  The player who loses all pieces or has no legal
+moves wins.
+
+See https://lichess.org/variant/antichess
+*/
+
+package chego
+
+/*
+genAntichessMoves appends legal Antichess moves to the given move list.  Since
+there are no checks, every pseudo-legal move is legal, unless a capture is
+available.  Pawns may promote to a king as well.
+
+Captures are compulsory, so the quiet piece moves are generated only if there
+are no captures.  Otherwise, the pseudo-legal moves of a position with many
+pieces could overflow the move list.
+*/
+func genAntichessMoves(p Position, l *MoveList) {
+	c := p.ActiveColor
+	enemies := p.Bitboards[12+(1^c)]
+
+	genAntichessPieceMoves(p, l, enemies)
+	captures := l.LastMoveIndex
+
+	genPawnMoves(p, l)
+
+	pawnMoves := l.LastMoveIndex
+	for i := captures; i < pawnMoves; i++ {
+		m := l.Moves[i]
+		if m.Type() == MovePromotion && m.PromoPiece() == PromotionQueen {
+			l.Push(NewPromotionMove(m.To(), m.From(), PromotionKing))
+		}
+	}
+
+	// Filter the pawn captures in place.
+	legal := captures
+	for i := captures; i < l.LastMoveIndex; i++ {
+		m := l.Moves[i]
+		if enemies&(1<<m.To()) != 0 || m.Type() == MoveEnPassant {
+			l.Moves[legal] = m
+			legal++
+		}
+	}
+
+	if legal > 0 {
+		l.LastMoveIndex = legal
+		return
+	}
+
+	// There are no captures, so every move is legal.
+	genAntichessPieceMoves(p, l, ^p.Bitboards[12+c])
+}
+
+/*
+genAntichessPieceMoves appends pseudo-legal moves for knights, bishops, rooks,
+queens and kings to the given move list.  Only the moves to the target squares
+are appended.  Pawns can promote to kings, so there may be several of them.
+*/
+func genAntichessPieceMoves(p Position, l *MoveList, targets uint64) {
+	c := p.ActiveColor
+	targets &^= p.Bitboards[12+c]
+	occupancy := p.Bitboards[14]
+
+	for i := PieceWKnight + c; i <= PieceWKing+c; i += 2 {
+		pieces := p.Bitboards[i]
+		for pieces > 0 {
+			from := popLSB(&pieces)
+
+			dests := uint64(0)
+			switch i {
+			case PieceWKnight, PieceBKnight:
+				dests = knightAttacks[from]
+			case PieceWBishop, PieceBBishop:
+				dests = lookupBishopAttacks(from, occupancy)
+			case PieceWRook, PieceBRook:
+				dests = lookupRookAttacks(from, occupancy)
+			case PieceWQueen, PieceBQueen:
+				dests = lookupQueenAttacks(from, occupancy)
+			case PieceWKing, PieceBKing:
+				dests = kingAttacks[from]
+			}
+
+			dests &= targets
+			for dests > 0 {
+				l.Push(NewMove(popLSB(&dests), from, MoveNormal))
+			}
+		}
+	}
+}
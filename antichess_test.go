@@ -0,0 +1,106 @@
+package chego
+
+import "testing"
+
+// Expected values are taken from the Fairy-Stockfish test suite.
+func TestAntichessPerft(t *testing.T) {
+	p := ParseVariantFEN(InitialAntichessPos, VariantAntichess)
+	expected := []int{20, 400, 8067, 153299}
+
+	for i, nodes := range expected {
+		if got := Perft(p, i+1); got != nodes {
+			t.Fatalf("depth %d: expected %d got %d\n%s", i+1, nodes, got, p)
+		}
+	}
+}
+
+func TestGenAntichessMoves(t *testing.T) {
+	testcases := []struct {
+		name     string
+		fen      string
+		expected []string
+	}{
+		{
+			"compulsory capture",
+			"4k3/8/8/3p4/4P3/8/8/4K3 w - - 0 1",
+			[]string{"e4d5"},
+		},
+		{
+			"king capture",
+			"8/8/8/8/8/8/3k4/4K3 w - - 0 1",
+			[]string{"e1d2"},
+		},
+		{
+			"king promotion",
+			"8/P7/8/8/8/8/8/7k w - - 0 1",
+			[]string{"a7a8n", "a7a8b", "a7a8r", "a7a8q", "a7a8k"},
+		},
+		// The pseudo-legal moves exceed the capacity of the move list.
+		{
+			"many pieces",
+			"R6R/3Q4/1Q4Q1/4Q3/2Q4Q/Q4Q2/3Q4/1BNN1KBk w - - 0 1",
+			[]string{"f3h1", "h4h1"},
+		},
+		{
+			"no castling",
+			"4k3/8/8/8/8/8/8/R3K3 w Q - 0 1",
+			[]string{"e1d1", "e1f1", "e1d2", "e1e2", "e1f2", "a1b1", "a1c1",
+				"a1d1", "a1a2", "a1a3", "a1a4", "a1a5", "a1a6", "a1a7", "a1a8"},
+		},
+	}
+
+	for _, tc := range testcases {
+		l := MoveList{}
+		GenLegalMoves(ParseVariantFEN(tc.fen, VariantAntichess), &l)
+
+		if int(l.LastMoveIndex) != len(tc.expected) {
+			t.Fatalf("%s: expected %d moves got %d", tc.name, len(tc.expected),
+				l.LastMoveIndex)
+		}
+		for _, uci := range tc.expected {
+			found := false
+			for m := range l.All() {
+				found = found || Move2UCI(m) == uci
+			}
+			if !found {
+				t.Fatalf("%s: move %s not found", tc.name, uci)
+			}
+		}
+	}
+}
+
+func TestKingPromotion(t *testing.T) {
+	p := ParseVariantFEN("1n5k/P7/8/8/8/8/8/8 w - - 0 1", VariantAntichess)
+
+	m, err := UCI2Move(p, "a7b8k")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Type() != MovePromotion || m.PromoPiece() != PromotionKing {
+		t.Fatalf("expected king promotion got type %d piece %d", m.Type(),
+			m.PromoPiece())
+	}
+
+	p.MakeMove(m)
+	if got := SerializeFEN(p); got != "1K5k/8/8/8/8/8/8/8 b - - 0 1" {
+		t.Fatalf("unexpected position %s", got)
+	}
+}
+
+func TestIsAntichessWin(t *testing.T) {
+	g := NewGame(Antichess{})
+	if g.IsAntichessWin() || g.Position.CastlingRights != 0 {
+		t.Fatalf("unexpected initial position\n%s", g.Position)
+	}
+
+	g.Position = ParseVariantFEN("8/8/8/8/8/8/8/4K2k w - - 0 1", VariantAntichess)
+	GenLegalMoves(g.Position, &g.LegalMoves)
+	g.PushMove(NewMove(SF1, SE1, MoveNormal))
+	g.PushMove(NewMove(SG1, SH1, MoveNormal))
+	g.PushMove(NewMove(SG1, SF1, MoveNormal))
+
+	// Black has lost all pieces.
+	if !g.IsAntichessWin() || g.IsCheckmate() || g.IsInsufficientMaterial() {
+		t.Fatalf("expected black to win\n%s", g.Position)
+	}
+}
@@ -0,0 +1,121 @@
+/*
+atomic.go implements the rules of Atomic chess.  Every capture causes an
+explosion which removes the capturing piece and all pieces except pawns standing
+next to the capture square.  The player who explodes the enemy king wins.
+
+See https://lichess.org/variant/atomic
+*/
+
+package chego
+
+/*
+genAtomicMoves appends legal Atomic moves to the given move list.  Kings cannot
+capture, since the capturing king would explode.  The player may leave the king
+in check if the kings are adjacent or if the move explodes the enemy king, but
+may never explode the own king.
+*/
+func genAtomicMoves(p Position, l *MoveList) {
+	c := p.ActiveColor
+	// The game is over if one of the kings has exploded.
+	if p.Bitboards[PieceWKing] == 0 || p.Bitboards[PieceBKing] == 0 {
+		return
+	}
+
+	king := bitScan(p.Bitboards[PieceWKing+c])
+	dests := kingAttacks[king] &^ p.Bitboards[14]
+	for dests > 0 {
+		l.Push(NewMove(popLSB(&dests), king, MoveNormal))
+	}
+
+	// Handle castling.
+	attacks := genAtomicAttacks(p)
+	short, long := CastlingWhiteShort, CastlingWhiteLong
+	if c == ColorBlack {
+		short, long = CastlingBlackShort, CastlingBlackLong
+	}
+	for _, side := range [2]CastlingRights{short, long} {
+		if p.canCastle(side, attacks) {
+			l.Push(p.newCastlingMove(side))
+		}
+	}
+
+	genPawnMoves(p, l)
+
+	genNormalMoves(p, l)
+
+	// Filter pseudo-legal moves in place.
+	legal := byte(0)
+	prev := p
+	for i := range l.LastMoveIndex {
+		m := l.Moves[i]
+
+		p.MakeMove(m)
+
+		if isAtomicKingSafe(p, c) {
+			l.Moves[legal] = m
+			legal++
+		}
+
+		p = prev
+	}
+
+	l.LastMoveIndex = legal
+}
+
+/*
+genAtomicAttacks returns a bitboard of squares attacked by the enemy pieces
+which the king of the active player cannot pass while castling.  The enemy king
+cannot capture, and the squares next to it are safe, since the piece capturing
+the king there would also explode the enemy king.
+*/
+func genAtomicAttacks(p Position) uint64 {
+	c := p.ActiveColor
+	enemyKing := p.Bitboards[PieceWKing+(1^c)]
+
+	// The king must be excluded from the occupancy, see genAttacks.
+	p.removePiece(PieceWKing+c, p.Bitboards[PieceWKing+c])
+	p.Bitboards[PieceWKing+(1^c)] = 0
+
+	return genAttacks(p.Bitboards, 1^c) &^ genKingAttacks(enemyKing)
+}
+
+/*
+isAtomicKingSafe reports whether the king of the specified color survived the
+move and isn't in check.
+*/
+func isAtomicKingSafe(p Position, c Color) bool {
+	king := p.Bitboards[PieceWKing+c]
+	if king == 0 {
+		return false
+	}
+
+	enemyKing := p.Bitboards[PieceWKing+(1^c)]
+	if enemyKing == 0 || kingAttacks[bitScan(king)]&enemyKing != 0 {
+		return true
+	}
+
+	return GenChecksCounter(p.Bitboards, 1^c) == 0
+}
+
+/*
+explode removes the piece standing on the capture square and all pieces except
+pawns standing next to it.  Castling rights of the exploded kings and rooks are
+disabled.
+*/
+func (p *Position) explode(square int) {
+	pawns := p.Bitboards[PieceWPawn] | p.Bitboards[PieceBPawn]
+	blast := (kingAttacks[square]&^pawns | 1<<square) & p.Bitboards[14]
+
+	for blast > 0 {
+		bb := uint64(1) << popLSB(&blast)
+		p.removePiece(p.GetPieceFromSquare(bb), bb)
+	}
+
+	for i := range 4 {
+		c := i / 2
+		if p.Bitboards[PieceWKing+c] == 0 ||
+			p.Bitboards[PieceWRook+c]&(1<<p.castlingRook(i)) == 0 {
+			p.CastlingRights &^= 1 << i
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package chego
+
+import "testing"
+
+// Expected values are taken from the Fairy-Stockfish test suite.
+func TestAtomicPerft(t *testing.T) {
+	testcases := []struct {
+		fen      string
+		depth    int
+		expected int
+	}{
+		{InitialPos, 4, 197326},
+		{"8/8/8/8/8/8/2k5/rR4KR w KQ - 0 1", 4, 61401},
+		{"r4b1r/2kb1N2/p2Bpnp1/8/2Pp3p/1P1PPP2/P5PP/R3K2R b KQ - 0 1", 2, 148},
+		{"1R4kr/4K3/8/8/8/8/8/8 b k - 0 1", 4, 17915},
+	}
+
+	for _, tc := range testcases {
+		p := ParseVariantFEN(tc.fen, VariantAtomic)
+		if got := Perft(p, tc.depth); got != tc.expected {
+			t.Fatalf("depth %d: expected %d got %d\n%s", tc.depth,
+				tc.expected, got, p)
+		}
+	}
+}
+
+func TestExplode(t *testing.T) {
+	testcases := []struct {
+		name     string
+		fen      string
+		move     Move
+		expected string
+	}{
+		{
+			"pawns survive",
+			"4k3/8/8/2pnb3/3PQ3/8/8/4K3 w - - 0 1",
+			NewMove(SD5, SE4, MoveNormal),
+			"4k3/8/8/2p5/3P4/8/8/4K3 b - - 0 1",
+		},
+		{
+			"en passant",
+			"4k3/8/8/3Pp3/8/8/8/4K3 w - e6 0 1",
+			NewMove(SE6, SD5, MoveEnPassant),
+			"4k3/8/8/8/8/8/8/4K3 b - - 0 1",
+		},
+		{
+			"castling rights",
+			"r3k2r/8/8/8/8/8/6p1/R3K2R b KQkq - 0 1",
+			NewMove(SH1, SG2, MoveNormal),
+			"r3k2r/8/8/8/8/8/8/R3K3 w Qkq - 0 2",
+		},
+		{
+			"king explodes",
+			"4k3/8/8/8/8/8/3q4/3QK3 w - - 0 1",
+			NewMove(SD2, SD1, MoveNormal),
+			"4k3/8/8/8/8/8/8/8 b - - 0 1",
+		},
+	}
+
+	for _, tc := range testcases {
+		p := ParseVariantFEN(tc.fen, VariantAtomic)
+		p.MakeMove(tc.move)
+		if got := SerializeFEN(p); got != tc.expected {
+			t.Fatalf("%s: expected %s got %s", tc.name, tc.expected, got)
+		}
+	}
+}
+
+func TestGenAtomicMoves(t *testing.T) {
+	testcases := []struct {
+		name     string
+		fen      string
+		expected int
+	}{
+		// The king cannot capture the queen, but may move next to the
+		// enemy king.
+		{"king captures", "8/8/8/8/8/3k4/4q3/4K3 w - - 0 1", 1},
+		// The queen cannot capture the checking pawn next to its own king.
+		{"own king", "4k3/8/8/8/8/8/3p4/3QK3 w - - 0 1", 3},
+		// Capturing next to the enemy king wins even in check.
+		{"explode king", "3rk3/8/8/8/8/8/8/3RK2q w - - 0 1", 3},
+		{"game over", "8/8/8/8/8/8/8/4K3 w - - 0 1", 0},
+	}
+
+	for _, tc := range testcases {
+		l := MoveList{}
+		GenLegalMoves(ParseVariantFEN(tc.fen, VariantAtomic), &l)
+		if int(l.LastMoveIndex) != tc.expected {
+			t.Fatalf("%s: expected %d moves got %d", tc.name, tc.expected,
+				l.LastMoveIndex)
+		}
+	}
+}
+
+func TestIsKingExploded(t *testing.T) {
+	g := NewGame(Atomic{})
+	for _, uci := range []string{"e2e4", "d7d5", "d1h5", "d5e4", "h5f7"} {
+		m, err := UCI2Move(g.Position, uci)
+		if err != nil {
+			t.Fatal(err)
+		}
+		g.PushMove(m)
+	}
+
+	if !g.IsKingExploded() || g.IsCheckmate() {
+		t.Fatalf("expected the black king to explode\n%s", g.Position)
+	}
+	if g.LegalMoves.LastMoveIndex != 0 {
+		t.Fatalf("expected no legal moves after the game end")
+	}
+}
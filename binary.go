@@ -0,0 +1,356 @@
+/*
+binary.go implements the compact binary encoding of the game, suitable for
+storing large game archives.
+
+The encoded game has the following layout:
+  - Format version byte.
+  - Flags byte (see the binaryFlag constants).
+  - Starting position as a length-prefixed FEN string.  Omitted if the game
+    starts from the initial position of its variant.
+  - Result, winner and the number of moves as uvarints.
+  - Clock values as zig-zag varints: white time, black time and time bonus.
+    Omitted if the game has no clock.
+  - Moves.  Each move is encoded either as a single byte holding its index in
+    the legal move list generated by the variant, or as the 16 bit [Move] in
+    little-endian order if some position has more than 256 legal moves.  If
+    the game has a clock, each move is followed by the difference between the
+    time left on the mover's clock and its value after the previous move, as
+    a zig-zag varint.
+*/
+
+package chego
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// binaryVersion is the version of the binary game encoding.
+const binaryVersion = 1
+
+const (
+	// The starting position FEN string is stored.
+	binaryFlagFEN = 1 << iota
+	// The starting position follows the Chess960 castling rules.
+	binaryFlagChess960
+	// The clock values are stored.
+	binaryFlagClocks
+	// Moves are stored as 16 bit values instead of legal move indices.
+	binaryFlagWideMoves
+)
+
+// errTruncated is returned when the encoded game ends unexpectedly.
+var errTruncated = errors.New("truncated game encoding")
+
+/*
+MarshalBinary encodes the game into the compact binary format.  The variant
+itself is not encoded: the game must be decoded with the same variant it was
+played with.
+
+Returns an error if one of the completed moves is illegal.
+*/
+func (g *Game) MarshalBinary() ([]byte, error) {
+	v := g.variant()
+	start := g.StartPosition
+	fen := v.SerializeFEN(start)
+
+	// Replay the game to get the legal move lists.
+	replay := newGame(start, v)
+	indices := make([]int, len(g.MoveStack))
+	flags := byte(0)
+	for i, completed := range g.MoveStack {
+		indices[i] = -1
+		j := 0
+		for m := range replay.LegalMoves.All() {
+			if m == completed.Move {
+				indices[i] = j
+				break
+			}
+			j++
+		}
+		if indices[i] < 0 {
+			return nil, fmt.Errorf("illegal move %s at ply %d",
+				Move2UCI(completed.Move), i+1)
+		}
+		if replay.LegalMoves.Len() > 256 {
+			flags |= binaryFlagWideMoves
+		}
+		replay.PushMove(completed.Move)
+	}
+
+	if fen != v.SerializeFEN(v.ParseFEN(v.StartFEN())) {
+		flags |= binaryFlagFEN
+	}
+	if start.Chess960 {
+		flags |= binaryFlagChess960
+	}
+	hasClocks := g.WhiteTime != 0 || g.BlackTime != 0 || g.TimeBonus != 0
+	for _, completed := range g.MoveStack {
+		hasClocks = hasClocks || completed.TimeLeft != 0
+	}
+	if hasClocks {
+		flags |= binaryFlagClocks
+	}
+
+	buf := make([]byte, 0, 16+len(g.MoveStack)*2)
+	buf = append(buf, binaryVersion, flags)
+	if flags&binaryFlagFEN != 0 {
+		buf = binary.AppendUvarint(buf, uint64(len(fen)))
+		buf = append(buf, fen...)
+	}
+	buf = binary.AppendUvarint(buf, uint64(g.Result))
+	buf = binary.AppendUvarint(buf, uint64(g.Winner))
+	buf = binary.AppendUvarint(buf, uint64(len(g.MoveStack)))
+	if hasClocks {
+		buf = binary.AppendVarint(buf, int64(g.WhiteTime))
+		buf = binary.AppendVarint(buf, int64(g.BlackTime))
+		buf = binary.AppendVarint(buf, int64(g.TimeBonus))
+	}
+
+	// Previous clock values indexed by color.
+	var prev [2]int
+	for i, completed := range g.MoveStack {
+		if flags&binaryFlagWideMoves != 0 {
+			buf = binary.LittleEndian.AppendUint16(buf, uint16(completed.Move))
+		} else {
+			buf = append(buf, byte(indices[i]))
+		}
+
+		if hasClocks {
+			c := (i + start.ActiveColor) % 2
+			buf = binary.AppendVarint(buf, int64(completed.TimeLeft-prev[c]))
+			prev[c] = completed.TimeLeft
+		}
+	}
+
+	return buf, nil
+}
+
+/*
+UnmarshalBinary decodes the game encoded by [Game.MarshalBinary] and replays
+its moves.  The game is played by the rules of the variant assigned to g, or
+[Standard] if no variant is assigned.
+
+Returns an error if the encoding is malformed or contains an illegal move.
+*/
+func (g *Game) UnmarshalBinary(data []byte) error {
+	v := g.variant()
+
+	if len(data) < 2 {
+		return errTruncated
+	}
+	version := data[0]
+	if version != binaryVersion {
+		return fmt.Errorf("unsupported game encoding version %d", version)
+	}
+	flags := data[1]
+	data = data[2:]
+
+	readUvarint := func() (int, error) {
+		x, n := binary.Uvarint(data)
+		if n <= 0 {
+			return 0, errTruncated
+		}
+		data = data[n:]
+		return int(x), nil
+	}
+
+	fen := v.StartFEN()
+	if flags&binaryFlagFEN != 0 {
+		n, err := readUvarint()
+		if err != nil {
+			return err
+		}
+		if n < 0 || len(data) < n {
+			return errTruncated
+		}
+		fen = string(data[:n])
+		data = data[n:]
+	}
+
+	start, err := tryParseFEN(v.ParseFEN, fen)
+	if err != nil {
+		return err
+	}
+	if flags&binaryFlagChess960 != 0 {
+		start.enableChess960()
+	}
+	if err := validateStart(start); err != nil {
+		return err
+	}
+
+	result, err := readUvarint()
+	if err != nil {
+		return err
+	}
+	winner, err := readUvarint()
+	if err != nil {
+		return err
+	}
+	numMoves, err := readUvarint()
+	if err != nil {
+		return err
+	}
+
+	var clocks [3]int
+	if flags&binaryFlagClocks != 0 {
+		for i := range clocks {
+			x, n := binary.Varint(data)
+			if n <= 0 {
+				return errTruncated
+			}
+			data = data[n:]
+			clocks[i] = int(x)
+		}
+	}
+
+	decoded := newGame(start, v)
+
+	var prev [2]int
+	for i := range numMoves {
+		var m Move
+		if flags&binaryFlagWideMoves != 0 {
+			if len(data) < 2 {
+				return errTruncated
+			}
+			m = Move(binary.LittleEndian.Uint16(data))
+			data = data[2:]
+			if !decoded.IsMoveLegal(m) {
+				return fmt.Errorf("illegal move %#04x at ply %d", uint16(m), i+1)
+			}
+		} else {
+			if len(data) < 1 {
+				return errTruncated
+			}
+			l := &decoded.LegalMoves
+			j := int(data[0])
+			switch {
+			case j < int(l.LastMoveIndex):
+				m = l.Moves[j]
+			case j < l.Len():
+				m = l.Drops[j-int(l.LastMoveIndex)]
+			default:
+				return fmt.Errorf("illegal move index %d at ply %d", j, i+1)
+			}
+			data = data[1:]
+		}
+
+		decoded.PushMove(m)
+
+		if flags&binaryFlagClocks != 0 {
+			delta, n := binary.Varint(data)
+			if n <= 0 {
+				return errTruncated
+			}
+			data = data[n:]
+
+			c := (i + start.ActiveColor) % 2
+			prev[c] += int(delta)
+			decoded.MoveStack[i].TimeLeft = prev[c]
+		}
+	}
+
+	if len(data) != 0 {
+		return fmt.Errorf("%d trailing bytes after the game encoding", len(data))
+	}
+
+	decoded.Result = Result(result)
+	decoded.Winner = winner
+	decoded.WhiteTime = clocks[0]
+	decoded.BlackTime = clocks[1]
+	decoded.TimeBonus = clocks[2]
+
+	if g.Clock != nil {
+		g.Clock.Stop()
+	}
+	*g = *decoded
+	return nil
+}
+
+/*
+validateStart checks the decoded starting position.  The FEN parser accepts
+some impossible positions, which the move generator doesn't expect.
+*/
+func validateStart(p Position) error {
+	var occupied uint64
+	for _, bb := range p.Bitboards[:12] {
+		if occupied&bb != 0 {
+			return errors.New("several pieces on the same square")
+		}
+		occupied |= bb
+	}
+
+	pawns := p.Bitboards[PieceWPawn] | p.Bitboards[PieceBPawn]
+	if p.Variant == VariantHorde {
+		// White pawns start on the first rank in Horde.
+		pawns &^= p.Bitboards[PieceWPawn] & RANK_1
+	}
+	if pawns&(RANK_1|RANK_8) != 0 {
+		return errors.New("pawns on the first or the eighth rank")
+	}
+
+	for c, name := range [2]string{"white", "black"} {
+		kings := CountBits(p.Bitboards[PieceWKing+c])
+		switch {
+		// Any piece can be lost in Antichess.
+		case p.Variant == VariantAntichess:
+		// White has no king in Horde.
+		case p.Variant == VariantHorde && c == ColorWhite && kings == 0:
+		case kings != 1:
+			return fmt.Errorf("%s has %d kings", name, kings)
+		}
+	}
+
+	for i := range 4 {
+		side := 1 << i
+		if p.CastlingRights&side == 0 {
+			continue
+		}
+
+		c := i / 2
+		rook := p.castlingRook(i)
+		rank := RANK_1 << (56 * c)
+		kings := p.Bitboards[PieceWKing+c] & rank
+		if !p.Chess960 {
+			// The king must stand on e1 or e8.
+			kings &= 1 << (SE1 + 56*c)
+		}
+		// The short castling rook stands to the right of the king.
+		isShort := i%2 == 0
+		if p.Bitboards[PieceWRook+c]&(1<<rook)&rank == 0 || kings == 0 ||
+			(rook > bitScan(kings)) != isShort {
+			return fmt.Errorf("castling rights without the king and the "+
+				"rook on %s", Square2String[rook&63])
+		}
+	}
+
+	if p.EPTarget != 0 && (p.EPTarget < SA3 || p.EPTarget > SH3) &&
+		(p.EPTarget < SA6 || p.EPTarget > SH6) {
+		return fmt.Errorf("invalid en passant target %d", p.EPTarget)
+	}
+
+	for _, cnt := range p.Pockets {
+		// Zobrist keys of the larger pockets aren't generated.
+		if cnt > 16 {
+			return fmt.Errorf("%d pieces of the same type in the pocket", cnt)
+		}
+	}
+
+	// The side to move could capture the king.
+	if p.Variant != VariantAntichess &&
+		p.Bitboards[PieceWKing+(1^p.ActiveColor)] != 0 &&
+		GenChecksCounter(p.Bitboards, p.ActiveColor) > 0 {
+		return errors.New("side not to move is in check")
+	}
+
+	return nil
+}
+
+// variant returns the variant of the game, or [Standard] if it isn't set.
+func (g *Game) variant() Variant {
+	if g.Variant == nil {
+		return Standard{}
+	}
+	return g.Variant
+}
@@ -0,0 +1,252 @@
+package chego
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// pgn returns the PGN string of the game with the Seven Tag Roster.
+func pgn(g *Game) string {
+	var b strings.Builder
+	b.WriteString("[Event \"?\"]\n[Site \"?\"]\n[Date \"????.??.??\"]\n" +
+		"[Round \"?\"]\n[White \"?\"]\n[Black \"?\"]\n[Result \"*\"]\n\n")
+
+	p := g.StartPosition
+	for i, completed := range g.MoveStack {
+		if i%2 == 0 {
+			b.WriteString(strconv.Itoa(i/2 + 1))
+			b.WriteString(". ")
+		}
+		b.WriteString(Move2SAN(p, completed.Move))
+		b.WriteByte(' ')
+		p.MakeMove(completed.Move)
+	}
+	b.WriteString("*\n")
+
+	return b.String()
+}
+
+func TestMarshalBinary(t *testing.T) {
+	testcases := []struct {
+		name    string
+		variant Variant
+		fen     string
+		moves   []string
+		clocks  bool
+	}{
+		{"opera game", Standard{}, InitialPos, operaGame, false},
+		{"clocks", Standard{}, InitialPos, operaGame[:9], true},
+		{"no moves", Standard{}, InitialPos, nil, true},
+		{
+			"custom position", Standard{}, "4k3/8/8/8/8/8/4P3/4K3 b - - 0 1",
+			[]string{"e8d7", "e2e4"}, false,
+		},
+		{
+			"chess960", Standard{}, Chess960FEN(0),
+			[]string{"e2e4", "e7e5"}, false,
+		},
+		{"crazyhouse", Crazyhouse{}, InitialCrazyhousePos, operaGame[:10], false},
+		// Drop moves exceed 256 legal moves, so the moves are stored as
+		// 16 bit values.
+		{
+			"wide moves", Crazyhouse{}, "2k5/8/8/8/8/8/8/4K3[QRBNPqrbnp] w - - 0 1",
+			[]string{"Q@e4", "Q@d5"}, true,
+		},
+		{"atomic", Atomic{}, InitialPos, []string{"e2e4", "d7d5", "e4d5"}, false},
+		{"horde", Horde{}, InitialHordePos, []string{"a4a5", "e7e5"}, false},
+		{
+			"antichess", Antichess{}, InitialAntichessPos,
+			[]string{"e2e3", "b7b5", "f1b5"}, false,
+		},
+	}
+
+	for _, tc := range testcases {
+		g := newGame(tc.variant.ParseFEN(tc.fen), tc.variant)
+		if tc.clocks {
+			g.WhiteTime, g.BlackTime, g.TimeBonus = 180, 180, 2
+		}
+		for i, uci := range tc.moves {
+			m, err := UCI2Move(g.Position, uci)
+			if err != nil {
+				t.Fatalf("%s: %v", tc.name, err)
+			}
+			g.PushMove(m)
+			if tc.clocks {
+				g.MoveStack[i].TimeLeft = 180 - i*3
+			}
+		}
+		g.Result, g.Winner = ResultResignation, ColorBlack
+
+		data, err := g.MarshalBinary()
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+
+		decoded := &Game{Variant: tc.variant}
+		if err := decoded.UnmarshalBinary(data); err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+
+		if decoded.Position != g.Position || decoded.StartPosition != g.StartPosition {
+			t.Fatalf("%s: expected %s got %s", tc.name, SerializeFEN(g.Position),
+				SerializeFEN(decoded.Position))
+		}
+		if len(decoded.MoveStack) != len(g.MoveStack) {
+			t.Fatalf("%s: expected %d moves got %d", tc.name, len(g.MoveStack),
+				len(decoded.MoveStack))
+		}
+		for i := range g.MoveStack {
+			if decoded.MoveStack[i] != g.MoveStack[i] {
+				t.Fatalf("%s: expected %v got %v at ply %d", tc.name,
+					g.MoveStack[i], decoded.MoveStack[i], i+1)
+			}
+		}
+		if decoded.Result != g.Result || decoded.Winner != g.Winner ||
+			decoded.WhiteTime != g.WhiteTime || decoded.BlackTime != g.BlackTime ||
+			decoded.TimeBonus != g.TimeBonus {
+			t.Fatalf("%s: game state is not restored", tc.name)
+		}
+	}
+}
+
+func TestUnmarshalBinaryErrors(t *testing.T) {
+	g := NewGame()
+	playUCI(t, g, operaGame[:4])
+	data, err := g.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testcases := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"version", append([]byte{0}, data[1:]...)},
+		{"truncated", data[:len(data)-1]},
+		{"trailing bytes", append(slices.Clone(data), 0)},
+		{"illegal index", append(slices.Clone(data[:len(data)-1]), 200)},
+		{
+			"malformed fen",
+			append([]byte{binaryVersion, binaryFlagFEN, 5}, "8/8/8\x00\x00\x00"...),
+		},
+		{
+			"malformed counters",
+			append([]byte{binaryVersion, binaryFlagFEN, 50},
+				"4k3/8/8/8/8/8/8/4K3 w - - x 1234567890123456789\x00\x00\x00"...),
+		},
+	}
+
+	for _, tc := range testcases {
+		if err := new(Game).UnmarshalBinary(tc.data); err == nil {
+			t.Fatalf("%s: expected error", tc.name)
+		}
+	}
+}
+
+func TestUnmarshalBinaryInvalidStart(t *testing.T) {
+	testcases := []struct {
+		name string
+		fen  string
+		err  string
+	}{
+		{"no king", "8/8/8/8/8/8/8/4K3 w - - 0 1", "black has 0 kings"},
+		{"two kings", "4k3/8/8/8/8/8/8/3KK3 w - - 0 1", "white has 2 kings"},
+		{
+			"castling without rook", "4k3/8/8/8/8/8/8/4K2R w KQ - 0 1",
+			"castling rights without the king and the rook on a1",
+		},
+		{
+			"castling without king", "r3k3/8/8/8/8/8/8/R2K4 w Qq - 0 1",
+			"castling rights without the king and the rook on a1",
+		},
+		{
+			"side not to move in check", "4k3/4R3/8/8/8/8/8/4K3 w - - 0 1",
+			"side not to move is in check",
+		},
+		{
+			"en passant target", "4k3/8/8/3pP3/8/8/8/4K3 w - d5 0 1",
+			"invalid en passant target",
+		},
+		{
+			"pawn on the last rank", "3Pk3/8/8/8/8/8/8/4K3 w - - 0 1",
+			"pawns on the first or the eighth rank",
+		},
+	}
+
+	for _, tc := range testcases {
+		data := append([]byte{binaryVersion, binaryFlagFEN, byte(len(tc.fen))},
+			tc.fen...)
+		data = append(data, 0, 0, 0)
+
+		err := new(Game).UnmarshalBinary(data)
+		if err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Fatalf("%s: expected %q, got %v", tc.name, tc.err, err)
+		}
+	}
+}
+
+// The untrusted input must never cause a panic.
+func TestUnmarshalBinaryCorrupted(t *testing.T) {
+	g := newGame(ParseFEN("r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1"), Standard{})
+	g.WhiteTime, g.BlackTime = 60, 60
+	playUCI(t, g, []string{"e1g1", "e8c8"})
+	data, err := g.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := range data {
+		for _, b := range []byte{0, 1, '/', '9', 'z', 0x7F, 0xFF} {
+			corrupted := slices.Clone(data)
+			corrupted[i] = b
+			new(Game).UnmarshalBinary(corrupted)
+		}
+		new(Game).UnmarshalBinary(data[:i])
+	}
+}
+
+func TestMarshalBinarySize(t *testing.T) {
+	g := NewGame()
+	playUCI(t, g, operaGame)
+
+	data, err := g.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Version, flags, result, winner and number of moves followed by 1 byte
+	// per move.
+	if expected := 5 + len(operaGame); len(data) != expected {
+		t.Fatalf("expected %d bytes got %d", expected, len(data))
+	}
+	if size := len(pgn(g)); len(data)*5 > size {
+		t.Fatalf("expected at least 5 times smaller encoding than %d byte PGN "+
+			"got %d bytes", size, len(data))
+	}
+}
+
+func BenchmarkMarshalBinary(b *testing.B) {
+	g := NewGame()
+	playUCI(b, g, operaGame)
+	data, _ := g.MarshalBinary()
+
+	for b.Loop() {
+		g.MarshalBinary()
+	}
+
+	b.ReportMetric(float64(len(data)), "bytes/game")
+	b.ReportMetric(float64(len(pgn(g))), "pgn-bytes/game")
+}
+
+func BenchmarkUnmarshalBinary(b *testing.B) {
+	g := NewGame()
+	playUCI(b, g, operaGame)
+	data, _ := g.MarshalBinary()
+
+	for b.Loop() {
+		new(Game).UnmarshalBinary(data)
+	}
+}
@@ -0,0 +1,255 @@
+/*
+bitboard.go defines the Bitboard and Square types with methods, which allow
+inspecting positions without copying the shifts and masks used by the move
+generator.
+*/
+
+package chego
+
+import (
+	"fmt"
+	"iter"
+	"strings"
+)
+
+/*
+Bitboard is a set of board squares, where each bit represents a single square:
+the LSB is the A1 square and the MSB is the H8 square.  The bitboards stored in
+[Position] can be converted to it directly:
+
+	knights := Bitboard(p.Bitboards[PieceWKnight])
+*/
+type Bitboard uint64
+
+// Square is an index of the board square: 0 is A1, 1 is B1, ..., 63 is H8.
+type Square int
+
+// Direction defines the board direction to shift the bitboards.
+type Direction int
+
+const (
+	North Direction = iota
+	South
+	East
+	West
+	NorthEast
+	NorthWest
+	SouthEast
+	SouthWest
+)
+
+// Count returns the number of squares within the bitboard.
+func (b Bitboard) Count() int {
+	return CountBits(uint64(b))
+}
+
+/*
+LSB returns the least significant square within the bitboard.
+
+NOTE: LSB returns H8 for the empty bitboard.
+*/
+func (b Bitboard) LSB() Square {
+	return Square(bitScan(uint64(b)))
+}
+
+/*
+PopLSB removes the least significant square from the bitboard and returns it.
+
+NOTE: PopLSB returns H8 for the empty bitboard.
+*/
+func (b *Bitboard) PopLSB() Square {
+	return Square(popLSB((*uint64)(b)))
+}
+
+// Has checks whether the square is within the bitboard.
+func (b Bitboard) Has(s Square) bool {
+	return b&s.Bitboard() != 0
+}
+
+// Set adds the square to the bitboard.
+func (b *Bitboard) Set(s Square) {
+	*b |= s.Bitboard()
+}
+
+// Clear removes the square from the bitboard.
+func (b *Bitboard) Clear(s Square) {
+	*b &^= s.Bitboard()
+}
+
+// Squares returns an iterator over the squares within the bitboard from A1 to H8.
+func (b Bitboard) Squares() iter.Seq[Square] {
+	return func(yield func(Square) bool) {
+		for b != 0 {
+			if !yield(b.PopLSB()) {
+				return
+			}
+		}
+	}
+}
+
+/*
+File returns the squares of the bitboard which lie on the specified file.
+Files are indexed from 0 (A) to 7 (H).
+*/
+func (b Bitboard) File(file int) Bitboard {
+	return b & (0x0101010101010101 << file)
+}
+
+/*
+Rank returns the squares of the bitboard which lie on the specified rank.
+Ranks are indexed from 0 (first) to 7 (eighth).
+*/
+func (b Bitboard) Rank(rank int) Bitboard {
+	return b & (0xFF << (8 * rank))
+}
+
+/*
+Shift moves each square of the bitboard one step in the specified direction.
+Squares which would leave the board, or wrap around to the other side of it,
+are removed.
+*/
+func (b Bitboard) Shift(d Direction) Bitboard {
+	switch d {
+	case North:
+		return b << 8
+	case South:
+		return b >> 8
+	case East:
+		return b & Bitboard(NOT_H_FILE) << 1
+	case West:
+		return b & Bitboard(NOT_A_FILE) >> 1
+	case NorthEast:
+		return b & Bitboard(NOT_H_FILE) << 9
+	case NorthWest:
+		return b & Bitboard(NOT_A_FILE) << 7
+	case SouthEast:
+		return b & Bitboard(NOT_H_FILE) >> 7
+	case SouthWest:
+		return b & Bitboard(NOT_A_FILE) >> 9
+	}
+	return b
+}
+
+/*
+String formats the bitboard as a board, where 'x' marks the squares within the
+bitboard and '.' marks the empty squares.  The eighth rank is printed first.
+*/
+func (b Bitboard) String() string {
+	var str strings.Builder
+
+	for rank := 7; rank >= 0; rank-- {
+		str.WriteByte(byte(rank) + 1 + '0')
+		str.WriteString("  ")
+
+		for file := range 8 {
+			if b.Has(NewSquare(file, rank)) {
+				str.WriteString("x  ")
+			} else {
+				str.WriteString(".  ")
+			}
+		}
+		str.WriteByte('\n')
+	}
+
+	str.WriteString("   a  b  c  d  e  f  g  h")
+
+	return str.String()
+}
+
+/*
+NewSquare returns the square standing on the specified file and rank.  Both
+are indexed from 0 to 7.
+*/
+func NewSquare(file, rank int) Square {
+	return Square(8*rank + file)
+}
+
+/*
+SquareFromString parses the square from its string representation, e.g. "e4".
+Returns an error if the string is not a valid square.
+*/
+func SquareFromString(str string) (Square, error) {
+	if len(str) != 2 || str[0] < 'a' || str[0] > 'h' ||
+		str[1] < '1' || str[1] > '8' {
+		return 0, fmt.Errorf("invalid square %q", str)
+	}
+	return NewSquare(int(str[0]-'a'), int(str[1]-'1')), nil
+}
+
+// File returns the file of the square from 0 (A) to 7 (H).
+func (s Square) File() int { return int(s) % 8 }
+
+// Rank returns the rank of the square from 0 (first) to 7 (eighth).
+func (s Square) Rank() int { return int(s) / 8 }
+
+/*
+Bitboard returns the bitboard with the single square.  Only the lower six bits
+of the square are used, so the squares outside of the board wrap around
+instead of panicking on the negative shift.
+*/
+func (s Square) Bitboard() Bitboard { return 1 << (s & 63) }
+
+// Mirror returns the square reflected vertically: A1 becomes A8 and so on.
+func (s Square) Mirror() Square { return s ^ 56 }
+
+/*
+String returns the string representation of the square, e.g. "e4".  Squares
+outside of the board are printed as "Square(64)".
+*/
+func (s Square) String() string {
+	if s < 0 || s > 63 {
+		return fmt.Sprintf("Square(%d)", int(s))
+	}
+	return Square2String[s]
+}
+
+/*
+Distance returns the number of king moves needed to get from one square to
+another on the empty board.
+*/
+func (s Square) Distance(other Square) int {
+	return max(abs(s.File()-other.File()), abs(s.Rank()-other.Rank()))
+}
+
+// abs returns the absolute value of x.
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+/*
+PawnAttacks returns the squares attacked by the pawn of the specified color
+standing on the square.
+
+NOTE: The attack functions require the initialized attack tables, see
+[InitAttackTables].
+*/
+func PawnAttacks(s Square, c Color) Bitboard {
+	return Bitboard(pawnAttacks[c][s])
+}
+
+// KnightAttacks returns the squares attacked by the knight standing on s.
+func KnightAttacks(s Square) Bitboard { return Bitboard(knightAttacks[s]) }
+
+// KingAttacks returns the squares attacked by the king standing on s.
+func KingAttacks(s Square) Bitboard { return Bitboard(kingAttacks[s]) }
+
+/*
+BishopAttacks returns the squares attacked by the bishop standing on s.  The
+attacks are blocked by the occupied squares, which are included in the result.
+*/
+func BishopAttacks(s Square, occupancy Bitboard) Bitboard {
+	return Bitboard(lookupBishopAttacks(int(s), uint64(occupancy)))
+}
+
+// RookAttacks returns the squares attacked by the rook, see [BishopAttacks].
+func RookAttacks(s Square, occupancy Bitboard) Bitboard {
+	return Bitboard(lookupRookAttacks(int(s), uint64(occupancy)))
+}
+
+// QueenAttacks returns the squares attacked by the queen, see [BishopAttacks].
+func QueenAttacks(s Square, occupancy Bitboard) Bitboard {
+	return Bitboard(lookupQueenAttacks(int(s), uint64(occupancy)))
+}
@@ -0,0 +1,145 @@
+package chego
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func TestBitboardSquares(t *testing.T) {
+	b := Bitboard(A1 | E4 | H8)
+
+	got := slices.Collect(b.Squares())
+	expected := []Square{SA1, SE4, SH8}
+	if !slices.Equal(got, expected) {
+		t.Fatalf("expected %v got %v", expected, got)
+	}
+
+	if b.Count() != 3 || b.LSB() != SA1 || !b.Has(SE4) || b.Has(SE5) {
+		t.Fatalf("invalid bitboard %x", uint64(b))
+	}
+
+	b.Clear(SE4)
+	b.Set(SD4)
+	if b != Bitboard(A1|D4|H8) {
+		t.Fatalf("expected %x got %x", A1|D4|H8, uint64(b))
+	}
+
+	if got := b.PopLSB(); got != SA1 || b != Bitboard(D4|H8) {
+		t.Fatalf("expected a1 and %x got %s and %x", D4|H8, got, uint64(b))
+	}
+}
+
+func TestBitboardShift(t *testing.T) {
+	testcases := []struct {
+		bitboard Bitboard
+		dir      Direction
+		expected Bitboard
+	}{
+		{Bitboard(E4), North, Bitboard(E5)},
+		{Bitboard(E4), South, Bitboard(E3)},
+		{Bitboard(H4 | E4), East, Bitboard(F4)},
+		{Bitboard(A4 | E4), West, Bitboard(D4)},
+		{Bitboard(H4 | E4), NorthEast, Bitboard(F5)},
+		{Bitboard(A4 | E4), NorthWest, Bitboard(D5)},
+		{Bitboard(H4 | E4), SouthEast, Bitboard(F3)},
+		{Bitboard(A4 | E4), SouthWest, Bitboard(D3)},
+		{Bitboard(E8), North, 0},
+	}
+
+	for _, tc := range testcases {
+		if got := tc.bitboard.Shift(tc.dir); got != tc.expected {
+			t.Fatalf("expected\n%s\ngot\n%s", tc.expected, got)
+		}
+	}
+}
+
+func TestBitboardFileRank(t *testing.T) {
+	b := Bitboard(RANK_2 | A1 | H8)
+
+	if got := b.File(0); got != Bitboard(A1|A2) {
+		t.Fatalf("expected %x got %x", A1|A2, uint64(got))
+	}
+	if got := b.Rank(7); got != Bitboard(H8) {
+		t.Fatalf("expected %x got %x", H8, uint64(got))
+	}
+}
+
+func TestBitboardString(t *testing.T) {
+	expected := "8  .  .  .  .  .  .  .  x  \n" +
+		"7  .  .  .  .  .  .  .  .  \n" +
+		"6  .  .  .  .  .  .  .  .  \n" +
+		"5  .  .  .  .  .  .  .  .  \n" +
+		"4  .  .  .  .  x  .  .  .  \n" +
+		"3  .  .  .  .  .  .  .  .  \n" +
+		"2  .  .  .  .  .  .  .  .  \n" +
+		"1  x  .  .  .  .  .  .  .  \n" +
+		"   a  b  c  d  e  f  g  h"
+
+	if got := Bitboard(A1 | E4 | H8).String(); got != expected {
+		t.Fatalf("expected\n%s\ngot\n%s", expected, got)
+	}
+}
+
+func TestSquare(t *testing.T) {
+	s, err := SquareFromString("e4")
+	if err != nil || s != SE4 {
+		t.Fatalf("expected e4 got %s, %v", s, err)
+	}
+
+	if s.File() != 4 || s.Rank() != 3 || s.Mirror() != SE5 ||
+		s.String() != "e4" || s.Bitboard() != Bitboard(E4) {
+		t.Fatalf("invalid square %d", s)
+	}
+
+	if d := Square(SA1).Distance(SH8); d != 7 {
+		t.Fatalf("expected distance 7 got %d", d)
+	}
+	if d := Square(SE4).Distance(SF6); d != 2 {
+		t.Fatalf("expected distance 2 got %d", d)
+	}
+
+	for _, str := range []string{"", "e", "i4", "e9", "e44"} {
+		if _, err := SquareFromString(str); err == nil {
+			t.Fatalf("expected error for %q", str)
+		}
+	}
+
+	if b := Square(-1).Bitboard(); b != Bitboard(H8) {
+		t.Fatalf("expected H8 got\n%s", b)
+	}
+
+	if got := Square(64).String(); got != "Square(64)" {
+		t.Fatalf("expected Square(64) got %s", got)
+	}
+	if got := fmt.Sprint(Square(-1)); got != "Square(-1)" {
+		t.Fatalf("expected Square(-1) got %s", got)
+	}
+}
+
+func TestAttacks(t *testing.T) {
+	occupancy := Bitboard(E6 | C4)
+
+	testcases := []struct {
+		name     string
+		got      Bitboard
+		expected Bitboard
+	}{
+		{"white pawn", PawnAttacks(SE4, ColorWhite), Bitboard(D5 | F5)},
+		{"black pawn", PawnAttacks(SA7, ColorBlack), Bitboard(B6)},
+		{"knight", KnightAttacks(SA1), Bitboard(B3 | C2)},
+		{"king", KingAttacks(SH8), Bitboard(G8 | G7 | H7)},
+		{"bishop", BishopAttacks(SD5, occupancy), Bitboard(E6 | C4 | C6 | B7 |
+			A8 | E4 | F3 | G2 | H1)},
+		{"rook", RookAttacks(SE4, occupancy), Bitboard(E5 | E6 | D4 | C4 | F4 |
+			G4 | H4 | E3 | E2 | E1)},
+		{"queen", QueenAttacks(SD5, occupancy), BishopAttacks(SD5, occupancy) |
+			RookAttacks(SD5, occupancy)},
+	}
+
+	for _, tc := range testcases {
+		if tc.got != tc.expected {
+			t.Fatalf("%s: expected\n%s\ngot\n%s", tc.name, tc.expected, tc.got)
+		}
+	}
+}
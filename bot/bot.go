@@ -0,0 +1,204 @@
+/*
+Package bot implements the computer opponent of the adjustable strength built
+on top of the [search] package.  The strength is set by the level from
+[MinLevel] to [MaxLevel]: the weaker bots search shallower, misjudge the
+positions by the evaluation noise and sometimes play the suboptimal moves.
+
+The bot plays the same moves given the same seed, level and game, unless its
+search is interrupted by the context.  Like the search, the bot only plays by
+the standard chess rules.
+
+Make sure to call [chego.InitAttackTables] and [chego.InitZobristKeys] ONCE
+before playing.
+*/
+package bot
+
+import (
+	"context"
+	"math"
+	"math/rand/v2"
+	"slices"
+
+	"github.com/BelikovArtem/chego"
+	"github.com/BelikovArtem/chego/eval"
+	"github.com/BelikovArtem/chego/search"
+)
+
+// Bounds of the bot levels.
+const (
+	MinLevel = 1
+	MaxLevel = 20
+)
+
+// hashMB is the size of the bot's transposition table in megabytes.
+const hashMB = 16
+
+// strength describes the play of a single level.
+type strength struct {
+	// Maximal search depth, zero means no limit.
+	depth int
+	// Maximal number of searched nodes per move.
+	nodes int
+	// Maximal evaluation noise in centipawns.
+	noise int
+	// Temperature of the move choice in centipawns: the move worse than the
+	// best one by the temperature is chosen e times less often.  Zero means
+	// the best move is always chosen.
+	temperature int
+}
+
+// levels holds the strength of each level, indexed by the level minus one.
+var levels = [MaxLevel]strength{
+	{1, 1000, 200, 300},
+	{1, 2000, 150, 250},
+	{2, 4000, 120, 200},
+	{2, 8000, 100, 160},
+	{3, 15000, 80, 130},
+	{3, 25000, 60, 100},
+	{4, 40000, 50, 80},
+	{4, 60000, 40, 60},
+	{5, 80000, 30, 45},
+	{5, 100000, 25, 35},
+	{6, 150000, 20, 25},
+	{6, 200000, 15, 18},
+	{7, 300000, 10, 12},
+	{7, 400000, 8, 8},
+	{8, 600000, 5, 5},
+	{9, 800000, 0, 0},
+	{10, 1200000, 0, 0},
+	{11, 2000000, 0, 0},
+	{12, 3000000, 0, 0},
+	{0, 5000000, 0, 0},
+}
+
+/*
+Bot chooses the moves at the specified level.  It keeps the search state
+between the moves, so use a separate Bot for each game.  The Bot is not safe
+for concurrent use.
+*/
+type Bot struct {
+	// Zobrist keys of the positions played before the current one, from
+	// the oldest to the latest.  Used to detect the repetitions.
+	History []uint64
+
+	level    int
+	seed     uint64
+	rng      *rand.Rand
+	searcher *search.Searcher
+}
+
+// New creates a new bot of the level clamped to [MinLevel, MaxLevel].  The
+// seed makes the choice of the moves reproducible.
+func New(level int, seed uint64) *Bot {
+	b := &Bot{
+		level:    min(max(level, MinLevel), MaxLevel),
+		seed:     seed,
+		rng:      rand.New(rand.NewPCG(seed, seed^0x9e3779b97f4a7c15)),
+		searcher: search.New(hashMB),
+	}
+	if b.strength().noise > 0 {
+		b.searcher.Evaluate = b.evaluate
+	}
+	return b
+}
+
+// Level returns the level of the bot.
+func (b *Bot) Level() int {
+	return b.level
+}
+
+/*
+Move returns the move the bot plays in the position, or zero if there are no
+legal moves or the position isn't played by the standard rules.  The context
+can be used to limit the thinking time.
+*/
+func (b *Bot) Move(ctx context.Context, p chego.Position) chego.Move {
+	if p.Variant != chego.VariantStandard {
+		return 0
+	}
+
+	s := b.strength()
+	limits := search.Limits{Depth: s.depth, Nodes: s.nodes}
+
+	if s.temperature == 0 {
+		b.searcher.History = b.History
+		return b.searcher.Search(ctx, p, limits).Move
+	}
+
+	l := chego.MoveList{}
+	chego.GenLegalMoves(p, &l)
+	moves := slices.Collect(l.All())
+	n := len(moves)
+	switch n {
+	case 0:
+		return 0
+	case 1:
+		return moves[0]
+	}
+
+	// Score each move by the search of the resulting position, so the
+	// alternatives to the best move are known.
+	limits.Depth = max(limits.Depth-1, 1)
+	limits.Nodes /= n
+	b.searcher.History = append(b.History[:len(b.History):len(b.History)],
+		chego.ZobristKey(p))
+
+	scores := make([]int, n)
+	best := -search.Infinity
+	for i, m := range moves {
+		child := p
+		child.MakeMove(m)
+		scores[i] = -b.searcher.Search(ctx, child, limits).Score
+		best = max(best, scores[i])
+	}
+
+	return moves[b.choose(scores, best, s.temperature)]
+}
+
+/*
+choose returns the index of the randomly chosen score.  The chances decrease
+exponentially with the distance from the best score.
+*/
+func (b *Bot) choose(scores []int, best, temperature int) int {
+	weights := make([]float64, len(scores))
+	var total float64
+	for i, score := range scores {
+		weights[i] = math.Exp(float64(score-best) / float64(temperature))
+		total += weights[i]
+	}
+
+	r := b.rng.Float64() * total
+	for i, w := range weights {
+		if r < w {
+			return i
+		}
+		r -= w
+	}
+	// Unreachable unless the rounding errors accumulate.
+	return len(scores) - 1
+}
+
+/*
+evaluate returns the static evaluation distorted by the noise.  The noise
+depends only on the position and the seed, so the same position is always
+misjudged in the same way.
+*/
+func (b *Bot) evaluate(p chego.Position) int {
+	noise := b.strength().noise
+	h := mix(chego.ZobristKey(p) ^ b.seed)
+	return eval.Evaluate(p) + int(h%uint64(2*noise+1)) - noise
+}
+
+// strength returns the strength of the bot's level.
+func (b *Bot) strength() strength {
+	return levels[b.level-1]
+}
+
+// mix scrambles the bits of x using the SplitMix64 finalizer.
+func mix(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	return x ^ x>>31
+}
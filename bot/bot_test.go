@@ -0,0 +1,122 @@
+package bot
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/BelikovArtem/chego"
+)
+
+func TestMain(m *testing.M) {
+	chego.InitAttackTables()
+	chego.InitZobristKeys()
+	os.Exit(m.Run())
+}
+
+// play returns the moves of the bots playing against each other.
+func play(white, black *Bot, plies int) []string {
+	p := chego.ParseFEN(chego.InitialPos)
+	var moves []string
+	for i := range plies {
+		b := white
+		if i%2 == 1 {
+			b = black
+		}
+
+		m := b.Move(context.Background(), p)
+		if m == 0 {
+			break
+		}
+		moves = append(moves, chego.Move2UCI(m))
+
+		white.History = append(white.History, chego.ZobristKey(p))
+		black.History = white.History
+		p.MakeMove(m)
+	}
+	return moves
+}
+
+func TestBotReproducible(t *testing.T) {
+	for _, level := range []int{1, 8} {
+		first := play(New(level, 42), New(level, 43), 8)
+		second := play(New(level, 42), New(level, 43), 8)
+
+		if len(first) != 8 {
+			t.Fatalf("level %d: expected 8 moves got %v", level, first)
+		}
+		for i := range first {
+			if first[i] != second[i] {
+				t.Fatalf("level %d: expected %v got %v", level, first, second)
+			}
+		}
+	}
+}
+
+func TestBotRandomness(t *testing.T) {
+	p := chego.ParseFEN(chego.InitialPos)
+
+	moves := make(map[chego.Move]bool)
+	for seed := range uint64(20) {
+		moves[New(MinLevel, seed).Move(context.Background(), p)] = true
+	}
+	if len(moves) < 3 {
+		t.Fatalf("expected various opening moves got %d", len(moves))
+	}
+}
+
+func TestBotMate(t *testing.T) {
+	// Back rank mate in one.
+	p := chego.ParseFEN("6k1/5ppp/8/8/8/8/5PPP/R5K1 w - - 0 1")
+
+	for _, level := range []int{12, 16, MaxLevel} {
+		for seed := range uint64(5) {
+			m := New(level, seed).Move(context.Background(), p)
+			if got := chego.Move2UCI(m); got != "a1a8" {
+				t.Fatalf("level %d: expected a1a8 got %s", level, got)
+			}
+		}
+	}
+}
+
+func TestBotNoMoves(t *testing.T) {
+	// Stalemate.
+	p := chego.ParseFEN("7k/5Q2/6K1/8/8/8/8/8 b - - 0 1")
+
+	for _, level := range []int{MinLevel, MaxLevel} {
+		if m := New(level, 1).Move(context.Background(), p); m != 0 {
+			t.Fatalf("level %d: expected no move got %s", level,
+				chego.Move2UCI(m))
+		}
+	}
+}
+
+func TestBotVariant(t *testing.T) {
+	p := chego.ParseVariantFEN(chego.InitialPos, chego.VariantAtomic)
+
+	for _, level := range []int{MinLevel, MaxLevel} {
+		if m := New(level, 1).Move(context.Background(), p); m != 0 {
+			t.Fatalf("level %d: expected no move got %s", level,
+				chego.Move2UCI(m))
+		}
+	}
+}
+
+func TestBotLevel(t *testing.T) {
+	testcases := []struct {
+		level    int
+		expected int
+	}{
+		{-5, MinLevel},
+		{MinLevel, MinLevel},
+		{10, 10},
+		{MaxLevel, MaxLevel},
+		{100, MaxLevel},
+	}
+
+	for _, tc := range testcases {
+		if got := New(tc.level, 0).Level(); got != tc.expected {
+			t.Fatalf("%d: expected %d got %d", tc.level, tc.expected, got)
+		}
+	}
+}
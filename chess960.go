@@ -0,0 +1,94 @@
+/*
+chess960.go implements the generation of Chess960 (Fischer Random) starting
+positions.
+
+See https://www.chessprogramming.org/Chess960
+*/
+
+package chego
+
+import "strings"
+
+/*
+Knight placements on the five squares left after placing bishops and the queen
+in Scharnagl's numbering scheme.
+*/
+var chess960Knights = [10][2]int{
+	{0, 1}, {0, 2}, {0, 3}, {0, 4}, {1, 2},
+	{1, 3}, {1, 4}, {2, 3}, {2, 4}, {3, 4},
+}
+
+/*
+Chess960FEN returns the FEN string of the Chess960 starting position with the
+specified index from 0 to 959 in Scharnagl's numbering scheme.  Index 518 is
+the standard chess starting position.  Castling rights are written in X-FEN
+format.
+
+Panics if the index is out of range, so validate the untrusted indices first.
+*/
+func Chess960FEN(index int) string {
+	if index < 0 || index > 959 {
+		panic("Chess960 position index is out of range")
+	}
+
+	var rank [8]byte
+
+	// Light-squared bishop on b, d, f or h file.
+	rank[2*(index%4)+1] = 'B'
+	index /= 4
+	// Dark-squared bishop on a, c, e or g file.
+	rank[2*(index%4)] = 'B'
+	index /= 4
+
+	// place puts the piece on the n-th empty square.
+	place := func(piece byte, n int) {
+		for file := range rank {
+			if rank[file] != 0 {
+				continue
+			}
+			if n == 0 {
+				rank[file] = piece
+				return
+			}
+			n--
+		}
+	}
+
+	place('Q', index%6)
+	index /= 6
+
+	// Place the second knight first, since placing the first one shifts
+	// the empty squares.
+	place('N', chess960Knights[index][1])
+	place('N', chess960Knights[index][0])
+
+	// King stands between the rooks on the remaining squares.
+	place('R', 0)
+	place('K', 0)
+	place('R', 0)
+
+	white := string(rank[:])
+	return strings.ToLower(white) + "/pppppppp/8/8/8/8/PPPPPPPP/" + white +
+		" w KQkq - 0 1"
+}
+
+/*
+Chess960Position returns the Chess960 starting position with the specified
+index from 0 to 959.  Panics if the index is out of range, see [Chess960FEN]
+for the details.
+*/
+func Chess960Position(index int) Position {
+	return ParseChess960FEN(Chess960FEN(index))
+}
+
+/*
+enableChess960 enables the Chess960 castling rules for the position, which
+could not be detected from the FEN string since the castling rooks stand in the
+corners.
+*/
+func (p *Position) enableChess960() {
+	if !p.Chess960 {
+		p.Chess960 = true
+		p.CastlingRooks = standardCastlingRooks
+	}
+}
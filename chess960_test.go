@@ -0,0 +1,181 @@
+package chego
+
+import "testing"
+
+func TestChess960FEN(t *testing.T) {
+	testcases := []struct {
+		index    int
+		expected string
+	}{
+		{0, "bbqnnrkr/pppppppp/8/8/8/8/PPPPPPPP/BBQNNRKR w KQkq - 0 1"},
+		{518, InitialPos},
+		{959, "rkrnnqbb/pppppppp/8/8/8/8/PPPPPPPP/RKRNNQBB w KQkq - 0 1"},
+	}
+
+	for _, tc := range testcases {
+		if got := Chess960FEN(tc.index); got != tc.expected {
+			t.Fatalf("position %d: expected %s got %s", tc.index, tc.expected, got)
+		}
+	}
+
+	for i := range 960 {
+		p := Chess960Position(i)
+		if !p.Chess960 || p.CastlingRights != 0xF {
+			t.Fatalf("position %d: invalid castling rules", i)
+		}
+		if got := Perft(p, 1); got < 18 || got > 21 {
+			t.Fatalf("position %d: unexpected number of legal moves %d", i, got)
+		}
+	}
+}
+
+func TestChess960FENOutOfRange(t *testing.T) {
+	for _, index := range []int{-1, 960} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Fatalf("position %d: expected panic", index)
+				}
+			}()
+			Chess960FEN(index)
+		}()
+	}
+}
+
+// Expected values are taken from https://www.chessprogramming.org/Chess960_Perft_Results
+func TestChess960Perft(t *testing.T) {
+	testcases := []struct {
+		fen      string
+		expected []int
+	}{
+		{"bqnb1rkr/pp3ppp/3ppn2/2p5/5P2/P2P4/NPP1P1PP/BQ1BNRKR w HFhf - 2 9",
+			[]int{21, 528, 12189}},
+		{"2nnrbkr/p1qppppp/8/1ppb4/6PP/3PP3/PPP2P2/BQNNRBKR w HEhe - 1 9",
+			[]int{21, 807, 18002}},
+		{"b1q1rrkb/pppppppp/3nn3/8/P7/1PPP4/4PPPP/BQNNRKRB w GE - 1 9",
+			[]int{20, 479, 10471}},
+		{"qbbnnrkr/2pp2pp/p7/1p2pp2/8/P3PP2/1PPP1KPP/QBBNNR1R w hf - 0 9",
+			[]int{22, 593, 13440}},
+		{"q1bnrkr1/ppppp2p/2n2p2/4b1p1/2NP4/8/PPP1PPPP/QNB1RRKB w ge - 1 9",
+			[]int{30, 860, 24566}},
+		{"qn1rbbkr/ppp2p1p/1n1pp1p1/8/3P4/P6P/1PP1PPPK/QNNRBB1R w hd - 2 9",
+			[]int{28, 811, 23175}},
+	}
+
+	for _, tc := range testcases {
+		p := ParseFEN(tc.fen)
+		for i, expected := range tc.expected {
+			if got := Perft(p, i+1); got != expected {
+				t.Fatalf("depth %d: expected %d got %d\n%s", i+1, expected,
+					got, p)
+			}
+		}
+	}
+}
+
+func TestChess960Castling(t *testing.T) {
+	testcases := []struct {
+		name     string
+		fen      string
+		uci      string
+		expected string
+	}{
+		{
+			"king and rook swap",
+			"4k3/8/8/8/8/8/8/5RK1 w F - 0 1",
+			"g1f1",
+			"4k3/8/8/8/8/8/8/2KR4 b - - 1 1",
+		},
+		{
+			"king stays on its square",
+			"4k3/8/8/8/8/8/8/6KR w H - 0 1",
+			"g1h1",
+			"4k3/8/8/8/8/8/8/5RK1 b - - 1 1",
+		},
+		{
+			"standard notation",
+			"1r2k2r/8/8/8/8/8/8/4K3 b hb - 0 1",
+			"e8g8",
+			"1r3rk1/8/8/8/8/8/8/4K3 w - - 1 2",
+		},
+	}
+
+	for _, tc := range testcases {
+		p := ParseFEN(tc.fen)
+
+		m, err := UCI2Move(p, tc.uci)
+		if err != nil || m.Type() != MoveCastling {
+			t.Fatalf("test \"%s\" failed: %s is not a castling move, %v", tc.name,
+				tc.uci, err)
+		}
+
+		p.MakeMove(m)
+		if got := SerializeFEN(p); got != tc.expected {
+			t.Fatalf("test \"%s\" failed: expected %s got %s", tc.name,
+				tc.expected, got)
+		}
+	}
+}
+
+func TestChess960CastlingThroughRook(t *testing.T) {
+	// After O-O-O the queen on a1 attacks the king on c1 along the rank, which
+	// was blocked by the castling rook on b1.
+	p := ParseFEN("4k3/8/8/8/8/8/8/qRK5 w B - 0 1")
+
+	if _, err := UCI2Move(p, "c1b1"); err == nil {
+		t.Fatalf("expected castling into check to be illegal")
+	}
+}
+
+func TestParseChess960FEN(t *testing.T) {
+	testcases := []struct {
+		fen      string
+		xfen     string
+		shredder string
+	}{
+		{
+			"rn2k1r1/ppp1pp1p/3p2p1/5bn1/P7/2N2B2/1PPPPP2/2BNK1RR w Gkq - 4 11",
+			"rn2k1r1/ppp1pp1p/3p2p1/5bn1/P7/2N2B2/1PPPPP2/2BNK1RR w Gkq - 4 11",
+			"rn2k1r1/ppp1pp1p/3p2p1/5bn1/P7/2N2B2/1PPPPP2/2BNK1RR w Gga - 4 11",
+		},
+		{
+			"bqnb1rkr/pp3ppp/3ppn2/2p5/5P2/P2P4/NPP1P1PP/BQ1BNRKR w HFhf - 2 9",
+			"bqnb1rkr/pp3ppp/3ppn2/2p5/5P2/P2P4/NPP1P1PP/BQ1BNRKR w KQkq - 2 9",
+			"bqnb1rkr/pp3ppp/3ppn2/2p5/5P2/P2P4/NPP1P1PP/BQ1BNRKR w HFhf - 2 9",
+		},
+		{
+			InitialPos,
+			InitialPos,
+			"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w HAha - 0 1",
+		},
+	}
+
+	for _, tc := range testcases {
+		p := ParseChess960FEN(tc.fen)
+
+		if got := SerializeFEN(p); got != tc.xfen {
+			t.Fatalf("expected %s got %s", tc.xfen, got)
+		}
+		if got := SerializeShredderFEN(p); got != tc.shredder {
+			t.Fatalf("expected %s got %s", tc.shredder, got)
+		}
+		if got := ParseFEN(tc.shredder); got != p {
+			t.Fatalf("expected %+v got %+v", p, got)
+		}
+	}
+}
+
+func TestNewChess960Game(t *testing.T) {
+	g := NewChess960Game(0)
+
+	m, err := UCI2Move(g.Position, "d1c3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.PushMove(m)
+	g.PopMove()
+
+	if !g.Position.Chess960 {
+		t.Fatalf("expected the game to keep Chess960 rules")
+	}
+}
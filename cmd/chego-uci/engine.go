@@ -0,0 +1,398 @@
+// engine.go implements the UCI protocol commands.
+
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/BelikovArtem/chego"
+	"github.com/BelikovArtem/chego/search"
+)
+
+const (
+	engineName   = "chego"
+	engineAuthor = "Artem Belikov"
+
+	// Default and maximal sizes of the transposition table in megabytes.
+	defaultHash = 16
+	maxHash     = 1024
+
+	// moveOverhead is the time reserved for the communication with the GUI.
+	moveOverhead = 50 * time.Millisecond
+	// minThinkTime is the minimal time spent on the move.
+	minThinkTime = 10 * time.Millisecond
+	// defaultMovesToGo is the expected number of moves until the end of the
+	// game, if the GUI doesn't send the movestogo parameter.
+	defaultMovesToGo = 30
+)
+
+/*
+engine executes the UCI commands.  The commands are read and executed by a
+single goroutine, while the search runs in the background so the engine
+can respond to the stop, ponderhit and isready commands.
+*/
+type engine struct {
+	// Guards the output, since the search goroutine writes the info lines
+	// and the best move.
+	mu  sync.Mutex
+	out io.Writer
+
+	searcher *search.Searcher
+	pos      chego.Position
+	// Zobrist keys of the positions played before pos.
+	history []uint64
+
+	// State of the running search.  done is nil if there is no search.
+	cancel context.CancelFunc
+	done   chan struct{}
+	// The best move is reported only after the release is closed.  The
+	// infinite and ponder searches hold it until the stop or ponderhit
+	// command.
+	release  chan struct{}
+	released bool
+	infinite bool
+	// Time to spend on the move after the ponderhit command.
+	ponderTime time.Duration
+	timer      *time.Timer
+}
+
+// newEngine creates the engine writing the responses to the out.
+func newEngine(out io.Writer) *engine {
+	return &engine{
+		out:      out,
+		searcher: search.New(defaultHash),
+		pos:      chego.ParseFEN(chego.InitialPos),
+	}
+}
+
+// run executes the commands from the input until the quit command or the end
+// of the input.
+func (e *engine) run(in io.Reader) {
+	s := bufio.NewScanner(in)
+	for s.Scan() {
+		fields := strings.Fields(s.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if !e.handle(fields[0], fields[1:]) {
+			break
+		}
+	}
+	e.stop()
+}
+
+// handle executes a single command and reports whether to continue.
+func (e *engine) handle(cmd string, args []string) bool {
+	switch cmd {
+	case "uci":
+		e.send("id name %s", engineName)
+		e.send("id author %s", engineAuthor)
+		e.send("option name Hash type spin default %d min 1 max %d",
+			defaultHash, maxHash)
+		e.send("option name Ponder type check default false")
+		e.send("uciok")
+
+	case "debug", "register":
+		// Nothing to do.
+
+	case "isready":
+		e.send("readyok")
+
+	case "setoption":
+		e.setOption(args)
+
+	case "ucinewgame":
+		e.stop()
+		e.searcher.Clear()
+
+	case "position":
+		e.stop()
+		e.position(args)
+
+	case "go":
+		e.stop()
+		e.goSearch(args)
+
+	case "stop":
+		e.stop()
+
+	case "ponderhit":
+		e.ponderhit()
+
+	case "d":
+		e.send("%s", e.pos.String())
+
+	case "quit":
+		return false
+
+	default:
+		e.send("info string unknown command %s", cmd)
+	}
+	return true
+}
+
+// send writes the line to the output.
+func (e *engine) send(format string, args ...any) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	fmt.Fprintf(e.out, format+"\n", args...)
+}
+
+// setOption executes the command "setoption name <id> [value <x>]".
+func (e *engine) setOption(args []string) {
+	name, value := parseOption(args)
+
+	switch strings.ToLower(name) {
+	case "hash":
+		mb, err := strconv.Atoi(value)
+		if err != nil || mb < 1 || mb > maxHash {
+			e.send("info string invalid hash size %q", value)
+			return
+		}
+		e.stop()
+		e.searcher = search.New(mb)
+
+	case "ponder":
+		// The GUI decides when to ponder.
+
+	default:
+		e.send("info string unknown option %q", name)
+	}
+}
+
+// parseOption returns the name and the value of the setoption command.  Both
+// can contain spaces.
+func parseOption(args []string) (name, value string) {
+	if len(args) == 0 || args[0] != "name" {
+		return "", ""
+	}
+	args = args[1:]
+
+	i := slices.Index(args, "value")
+	if i < 0 {
+		return strings.Join(args, " "), ""
+	}
+	return strings.Join(args[:i], " "), strings.Join(args[i+1:], " ")
+}
+
+/*
+position executes the command "position [fen <fen> | startpos] moves ...".
+The moves are played until the first illegal one.
+*/
+func (e *engine) position(args []string) {
+	i := slices.Index(args, "moves")
+	if i < 0 {
+		i = len(args)
+	}
+
+	var p chego.Position
+	switch {
+	case len(args) > 0 && args[0] == "startpos":
+		p = chego.ParseFEN(chego.InitialPos)
+
+	case len(args) > 1 && args[0] == "fen":
+		fen := strings.Join(args[1:i], " ")
+		if err := p.UnmarshalText([]byte(fen)); err != nil {
+			e.send("info string %v", err)
+			return
+		}
+
+	default:
+		e.send("info string invalid position command")
+		return
+	}
+
+	var history []uint64
+	for _, uci := range args[min(i+1, len(args)):] {
+		m, err := chego.UCI2Move(p, uci)
+		if err != nil {
+			e.send("info string %v", err)
+			break
+		}
+		history = append(history, chego.ZobristKey(p))
+		p.MakeMove(m)
+	}
+
+	e.pos, e.history = p, history
+}
+
+/*
+goSearch executes the command "go [wtime <x>] [btime <x>] [winc <x>]
+[binc <x>] [movestogo <x>] [depth <x>] [nodes <x>] [movetime <x>] [infinite]
+[ponder]" and starts the search in the background.
+*/
+func (e *engine) goSearch(args []string) {
+	var ponder bool
+	e.infinite = false
+
+	params := make(map[string]int)
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "infinite":
+			e.infinite = true
+
+		case "ponder":
+			ponder = true
+
+		case "wtime", "btime", "winc", "binc", "movestogo", "depth", "nodes",
+			"movetime":
+			if i+1 < len(args) {
+				params[args[i]], _ = strconv.Atoi(args[i+1])
+				i++
+			}
+		}
+	}
+
+	limits := search.Limits{Depth: params["depth"], Nodes: params["nodes"]}
+
+	side := "w"
+	if e.pos.ActiveColor == chego.ColorBlack {
+		side = "b"
+	}
+
+	var budget time.Duration
+	if t, ok := params["movetime"]; ok {
+		budget = max(ms(t)-moveOverhead, minThinkTime)
+	} else if t, ok := params[side+"time"]; ok && !e.infinite {
+		budget = timeBudget(ms(t), ms(params[side+"inc"]),
+			params["movestogo"])
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	e.cancel = cancel
+	e.done = make(chan struct{})
+	e.release = make(chan struct{})
+	e.released = false
+	e.ponderTime = 0
+
+	if ponder {
+		e.ponderTime = budget
+	} else {
+		if budget > 0 {
+			e.timer = time.AfterFunc(budget, cancel)
+		}
+		if !e.infinite {
+			e.releaseBestMove()
+		}
+	}
+
+	s := e.searcher
+	s.History = e.history
+	s.OnInfo = e.info
+
+	go func(p chego.Position, done, release chan struct{}) {
+		defer close(done)
+
+		res := s.Search(ctx, p, limits)
+		<-release
+		e.bestMove(res)
+	}(e.pos, e.done, e.release)
+}
+
+/*
+timeBudget returns the time to spend on the move given the time left on the
+clock, the increment and the number of moves until the next time control.
+*/
+func timeBudget(left, inc time.Duration, movesToGo int) time.Duration {
+	if movesToGo <= 0 {
+		movesToGo = defaultMovesToGo
+	}
+	budget := left/time.Duration(movesToGo) + inc*3/4
+	return max(min(budget, left-moveOverhead), minThinkTime)
+}
+
+// ms converts the milliseconds sent by the GUI into the duration.
+func ms(n int) time.Duration {
+	return time.Duration(n) * time.Millisecond
+}
+
+/*
+ponderhit executes the ponderhit command: the opponent played the expected
+move, so the ponder search turns into the normal one.
+*/
+func (e *engine) ponderhit() {
+	if e.done == nil || e.released {
+		return
+	}
+
+	if e.ponderTime > 0 {
+		e.timer = time.AfterFunc(e.ponderTime, e.cancel)
+	}
+	if !e.infinite {
+		e.releaseBestMove()
+	}
+}
+
+// stop stops the running search and waits until it reports the best move.
+func (e *engine) stop() {
+	if e.done == nil {
+		return
+	}
+
+	e.cancel()
+	e.releaseBestMove()
+	<-e.done
+
+	if e.timer != nil {
+		e.timer.Stop()
+		e.timer = nil
+	}
+	e.done = nil
+}
+
+// releaseBestMove allows the search to report the best move.
+func (e *engine) releaseBestMove() {
+	if !e.released {
+		close(e.release)
+		e.released = true
+	}
+}
+
+// info reports the completed iteration of the search.
+func (e *engine) info(info search.Info) {
+	score := fmt.Sprintf("cp %d", info.Score)
+	if search.IsMate(info.Score) {
+		score = fmt.Sprintf("mate %d", search.MateIn(info.Score))
+	}
+
+	elapsed := info.Time.Milliseconds()
+	nps := int64(info.Nodes) * 1000 / max(elapsed, 1)
+
+	line := fmt.Sprintf("info depth %d score %s nodes %d nps %d time %d",
+		info.Depth, score, info.Nodes, nps, elapsed)
+	if len(info.PV) > 0 {
+		line += " pv " + formatMoves(info.PV)
+	}
+	e.send("%s", line)
+}
+
+// bestMove reports the result of the search.
+func (e *engine) bestMove(res search.Result) {
+	switch {
+	case res.Move == 0:
+		// No legal moves.
+		e.send("bestmove 0000")
+	case len(res.PV) > 1:
+		e.send("bestmove %s ponder %s", chego.Move2UCI(res.Move),
+			chego.Move2UCI(res.PV[1]))
+	default:
+		e.send("bestmove %s", chego.Move2UCI(res.Move))
+	}
+}
+
+// formatMoves joins the moves in the UCI notation.
+func formatMoves(moves []chego.Move) string {
+	uci := make([]string, len(moves))
+	for i, m := range moves {
+		uci[i] = chego.Move2UCI(m)
+	}
+	return strings.Join(uci, " ")
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/BelikovArtem/chego"
+)
+
+func TestMain(m *testing.M) {
+	chego.InitAttackTables()
+	chego.InitZobristKeys()
+	os.Exit(m.Run())
+}
+
+// client talks to the engine running in the background, like the GUI does.
+type client struct {
+	t    *testing.T
+	in   *io.PipeWriter
+	outR *io.PipeReader
+	out  *bufio.Scanner
+	exit chan struct{}
+}
+
+func newClient(t *testing.T) *client {
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+
+	c := &client{t: t, in: inW, outR: outR, out: bufio.NewScanner(outR),
+		exit: make(chan struct{})}
+	go func() {
+		newEngine(outW).run(inR)
+		outW.Close()
+		close(c.exit)
+	}()
+	return c
+}
+
+func (c *client) send(cmd string) {
+	if _, err := io.WriteString(c.in, cmd+"\n"); err != nil {
+		c.t.Fatalf("%s: %v", cmd, err)
+	}
+}
+
+// expect reads the output until the line with the prefix and returns all the
+// lines read.
+func (c *client) expect(prefix string) []string {
+	var lines []string
+	for c.out.Scan() {
+		lines = append(lines, c.out.Text())
+		if strings.HasPrefix(c.out.Text(), prefix) {
+			return lines
+		}
+	}
+	c.t.Fatalf("expected %q got %q", prefix, lines)
+	return nil
+}
+
+// quit stops the engine and waits until it exits.
+func (c *client) quit() {
+	c.send("quit")
+	// The engine may still write the best move.
+	go io.Copy(io.Discard, c.outR)
+	<-c.exit
+}
+
+func TestHandshake(t *testing.T) {
+	c := newClient(t)
+
+	c.send("uci")
+	lines := c.expect("uciok")
+	if lines[0] != "id name chego" ||
+		!strings.Contains(strings.Join(lines, "\n"), "option name Hash") {
+		t.Fatalf("unexpected response %q", lines)
+	}
+
+	c.send("setoption name Hash value 1")
+	c.send("ucinewgame")
+	c.send("isready")
+	if lines = c.expect("readyok"); len(lines) != 1 {
+		t.Fatalf("unexpected response %q", lines)
+	}
+
+	c.send("setoption name Hash value 0")
+	c.expect("info string invalid hash size")
+	c.send("setoption name Style value Aggressive")
+	c.expect("info string unknown option")
+	c.send("foo")
+	c.expect("info string unknown command foo")
+	c.quit()
+}
+
+func TestGoDepth(t *testing.T) {
+	c := newClient(t)
+
+	c.send("position startpos moves e2e4 e7e5")
+	c.send("go depth 3")
+	lines := c.expect("bestmove")
+	if len(lines) != 4 || !strings.HasPrefix(lines[2], "info depth 3 score cp") {
+		t.Fatalf("unexpected response %q", lines)
+	}
+
+	// Back rank mate.
+	c.send("position fen 6k1/5ppp/8/8/8/8/5PPP/R5K1 w - - 0 1")
+	c.send("go depth 3")
+	lines = c.expect("bestmove")
+	if !strings.Contains(lines[0], "score mate 1") ||
+		lines[len(lines)-1] != "bestmove a1a8" {
+		t.Fatalf("unexpected response %q", lines)
+	}
+
+	// The black player is mated after the moves.
+	c.send("position startpos moves f2f3 e7e5 g2g4 d8h4")
+	c.send("go depth 2")
+	lines = c.expect("bestmove")
+	if !strings.Contains(lines[0], "score mate 0") ||
+		lines[len(lines)-1] != "bestmove 0000" {
+		t.Fatalf("unexpected response %q", lines)
+	}
+	c.quit()
+}
+
+func TestPosition(t *testing.T) {
+	c := newClient(t)
+
+	c.send("position fen 8/8/8")
+	c.expect("info string invalid FEN string")
+
+	// The moves are played until the illegal one.
+	c.send("position startpos moves e2e4 e2e4")
+	c.expect("info string illegal move")
+	c.send("d")
+	lines := c.expect("Active color")
+	if !strings.Contains(lines[len(lines)-1], "black") {
+		t.Fatalf("unexpected position %q", lines)
+	}
+	c.quit()
+}
+
+func TestGoTime(t *testing.T) {
+	testcases := []string{
+		"go movetime 100",
+		"go wtime 3000 btime 3000 winc 100 binc 100",
+		"go wtime 1000 btime 1000 movestogo 10",
+		"go nodes 2000",
+	}
+
+	c := newClient(t)
+	for _, cmd := range testcases {
+		start := time.Now()
+		c.send("position startpos")
+		c.send(cmd)
+		c.expect("bestmove")
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Fatalf("%s: expected to stop in time got %v", cmd, elapsed)
+		}
+	}
+	c.quit()
+}
+
+func TestStop(t *testing.T) {
+	c := newClient(t)
+
+	// The infinite search doesn't report the best move until stopped, even
+	// if it completes.
+	c.send("position fen 6k1/5ppp/8/8/8/8/5PPP/R5K1 w - - 0 1")
+	c.send("go infinite depth 1")
+	c.send("isready")
+	c.expect("readyok")
+	time.Sleep(50 * time.Millisecond)
+	c.send("stop")
+	c.expect("bestmove a1a8")
+
+	c.send("position startpos")
+	c.send("go ponder wtime 1000 btime 1000")
+	time.Sleep(50 * time.Millisecond)
+	c.send("ponderhit")
+	start := time.Now()
+	c.expect("bestmove")
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("expected to stop after ponderhit got %v", elapsed)
+	}
+
+	// The quit command stops the search.
+	c.send("go infinite")
+	c.quit()
+}
+
+func TestTimeBudget(t *testing.T) {
+	testcases := []struct {
+		left, inc time.Duration
+		movesToGo int
+		expected  time.Duration
+	}{
+		{30 * time.Second, 0, 0, time.Second},
+		{10 * time.Second, time.Second, 10, 1750 * time.Millisecond},
+		{100 * time.Millisecond, 0, 1, 50 * time.Millisecond},
+		{0, 0, 0, minThinkTime},
+	}
+
+	for _, tc := range testcases {
+		if got := timeBudget(tc.left, tc.inc, tc.movesToGo); got != tc.expected {
+			t.Fatalf("%+v: expected %v got %v", tc, tc.expected, got)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+/*
+Chego-uci runs the chego search engine speaking the Universal Chess Interface
+protocol over the standard input and output, so it can be plugged into the
+chess GUIs and tournament managers.
+
+Usage:
+
+	go run ./cmd/chego-uci
+
+Supported commands: uci, debug, isready, setoption, register, ucinewgame,
+position, go, stop, ponderhit and quit.  The d command prints the current
+position.
+*/
+package main
+
+import (
+	"os"
+
+	"github.com/BelikovArtem/chego"
+)
+
+// main runs the engine until the quit command or the end of the input.
+func main() {
+	chego.InitAttackTables()
+	chego.InitZobristKeys()
+
+	newEngine(os.Stdout).run(os.Stdin)
+}
@@ -0,0 +1,192 @@
+package chego
+
+import "testing"
+
+func TestCrazyhouseFEN(t *testing.T) {
+	testcases := []struct {
+		fen      string
+		expected string
+	}{
+		{InitialCrazyhousePos, InitialCrazyhousePos},
+		{
+			"r3k2r/ppp2ppp/8/8/8/8/PPP2PPP/R2Q~K2R[QNPnpp] b KQkq - 0 1",
+			"r3k2r/ppp2ppp/8/8/8/8/PPP2PPP/R2Q~K2R[QNPnpp] b KQkq - 0 1",
+		},
+		// Pockets written as the ninth rank.
+		{
+			"r3k2r/ppp2ppp/8/8/8/8/PPP2PPP/R2Q~K2R/pQnPpN b KQkq - 0 1",
+			"r3k2r/ppp2ppp/8/8/8/8/PPP2PPP/R2Q~K2R[QNPnpp] b KQkq - 0 1",
+		},
+	}
+
+	for _, tc := range testcases {
+		p := ParseFEN(tc.fen)
+		if p.Variant != VariantCrazyhouse {
+			t.Fatalf("%s: expected Crazyhouse position", tc.fen)
+		}
+		if got := SerializeFEN(p); got != tc.expected {
+			t.Fatalf("expected %s got %s", tc.expected, got)
+		}
+	}
+
+	p := ParseFEN("r3k2r/ppp2ppp/8/8/8/8/PPP2PPP/R2Q~K2R[QNPnpp] b KQkq - 0 1")
+	if p.Promoted != D1 {
+		t.Fatalf("expected promoted %x got %x", D1, p.Promoted)
+	}
+	if p.Pockets[PieceBPawn] != 2 || p.Pockets[PieceWQueen] != 1 ||
+		p.Pockets[PieceBQueen] != 0 {
+		t.Fatalf("unexpected pockets %v", p.Pockets)
+	}
+}
+
+// Expected values are taken from the Fairy-Stockfish test suite.
+func TestCrazyhousePerft(t *testing.T) {
+	testcases := []struct {
+		fen      string
+		expected []int
+	}{
+		{InitialCrazyhousePos, []int{20, 400, 8902, 197281}},
+		{"2k5/8/8/8/8/8/8/4K3[QRBNPqrbnp] w - - 0 1", []int{301, 75353}},
+	}
+
+	for _, tc := range testcases {
+		p := ParseFEN(tc.fen)
+		for i, expected := range tc.expected {
+			if got := Perft(p, i+1); got != expected {
+				t.Fatalf("depth %d: expected %d got %d\n%s", i+1, expected,
+					got, p)
+			}
+		}
+	}
+}
+
+func TestGenDropMoves(t *testing.T) {
+	testcases := []struct {
+		name     string
+		fen      string
+		expected int
+	}{
+		// 3 king moves and 3 knight drops blocking the check.
+		{"block slider", "4k3/8/8/8/8/8/8/r3K3[N] w - - 0 1", 6},
+		// 5 king moves, the check cannot be blocked.
+		{"knight check", "4k3/8/8/8/8/8/2n5/4K3[Q] w - - 0 1", 5},
+		// 5 king moves and 48 pawn drops.
+		{"pawn drops", "4k3/8/8/8/8/8/8/4K3[P] w - - 0 1", 53},
+		// Pieces from the enemy pocket cannot be dropped.
+		{"enemy pocket", "4k3/8/8/8/8/8/8/4K3[q] w - - 0 1", 5},
+		// 5 king moves, 4*62 piece drops and 48 pawn drops exceed the
+		// capacity of the board move list.
+		{"full pocket", "4k3/8/8/8/8/8/8/4K3[QRBNP] w - - 0 1", 301},
+	}
+
+	for _, tc := range testcases {
+		l := MoveList{}
+		GenLegalMoves(ParseFEN(tc.fen), &l)
+		if l.Len() != tc.expected {
+			t.Fatalf("%s: expected %d moves got %d", tc.name, tc.expected,
+				l.Len())
+		}
+		for _, m := range l.Moves[:l.LastMoveIndex] {
+			if m.IsDrop() {
+				t.Fatalf("%s: drop %s among the board moves", tc.name,
+					Move2UCI(m))
+			}
+		}
+	}
+}
+
+func TestCrazyhouseMakeMove(t *testing.T) {
+	testcases := []struct {
+		name     string
+		fen      string
+		move     Move
+		expected string
+	}{
+		{
+			"capture",
+			"4k3/8/8/8/8/8/3q4/4K3[] w - - 0 1",
+			NewMove(SD2, SE1, MoveNormal),
+			"4k3/8/8/8/8/8/3K4/8[Q] b - - 0 1",
+		},
+		{
+			"promoted capture",
+			"4k3/8/8/8/8/8/3q~4/4K3[] w - - 0 1",
+			NewMove(SD2, SE1, MoveNormal),
+			"4k3/8/8/8/8/8/3K4/8[P] b - - 0 1",
+		},
+		{
+			"en passant",
+			"4k3/8/8/3Pp3/8/8/8/4K3[] w - e6 0 1",
+			NewMove(SE6, SD5, MoveEnPassant),
+			"4k3/8/4P3/8/8/8/8/4K3[P] b - - 0 1",
+		},
+		{
+			"promotion",
+			"1n2k3/P7/8/8/8/8/8/4K3[] w - - 0 1",
+			NewPromotionMove(SB8, SA7, PromotionRook),
+			"1R~2k3/8/8/8/8/8/8/4K3[N] b - - 0 1",
+		},
+		{
+			"promoted move",
+			"R~3k3/8/8/8/8/8/8/4K3[] w - - 0 1",
+			NewMove(SA1, SA8, MoveNormal),
+			"4k3/8/8/8/8/8/8/R~3K3[] b - - 1 1",
+		},
+		{
+			"drop",
+			"4k3/8/8/8/8/8/8/4K3[Nn] b - - 3 1",
+			NewDropMove(SF6, PieceBKnight),
+			"4k3/8/5n2/8/8/8/8/4K3[N] w - - 4 2",
+		},
+	}
+
+	for _, tc := range testcases {
+		p := ParseFEN(tc.fen)
+		p.MakeMove(tc.move)
+		if got := SerializeFEN(p); got != tc.expected {
+			t.Fatalf("%s: expected %s got %s", tc.name, tc.expected, got)
+		}
+	}
+}
+
+func TestCrazyhouseUCI(t *testing.T) {
+	p := ParseFEN("4k3/8/8/8/8/8/8/4K3[Pn] w - - 0 1")
+
+	m, err := UCI2Move(p, "P@e4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m != NewDropMove(SE4, PieceWPawn) || Move2UCI(m) != "P@e4" {
+		t.Fatalf("unexpected move %s", Move2UCI(m))
+	}
+
+	// Pieces from the enemy pocket cannot be dropped.
+	if _, err := UCI2Move(p, "N@f3"); err == nil {
+		t.Fatalf("expected error")
+	}
+}
+
+func TestCrazyhouseGame(t *testing.T) {
+	g := NewGame(Crazyhouse{})
+	for _, uci := range []string{"e2e4", "d7d5", "e4d5", "d8d5", "P@e4"} {
+		m, err := UCI2Move(g.Position, uci)
+		if err != nil {
+			t.Fatal(err)
+		}
+		g.PushMove(m)
+	}
+
+	expected := "rnb1kbnr/ppp1pppp/8/3q4/4P3/8/PPPP1PPP/RNBQKBNR[p] b KQkq - 0 3"
+	if got := SerializeFEN(g.Position); got != expected {
+		t.Fatalf("expected %s got %s", expected, got)
+	}
+	if g.IsInsufficientMaterial() {
+		t.Fatalf("expected sufficient material")
+	}
+
+	g.PopMove()
+	expected = "rnb1kbnr/ppp1pppp/8/3q4/8/8/PPPP1PPP/RNBQKBNR[Pp] w KQkq - 0 3"
+	if got := SerializeFEN(g.Position); got != expected {
+		t.Fatalf("expected %s got %s", expected, got)
+	}
+}
@@ -0,0 +1,308 @@
+/*
+Package eval implements the static evaluation of chess positions.  The score is
+a sum of the hand-crafted terms: material, piece-square tables, mobility, pawn
+structure, king safety and bishop pair.  Each term has the middlegame and
+endgame scores, which are interpolated by the game phase, so the king hides in
+the middlegame and becomes active in the endgame.
+
+The evaluation follows the standard chess principles and is only a rough guess
+in the variant positions.
+
+Make sure to call [chego.InitAttackTables] ONCE before evaluating positions.
+*/
+package eval
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/BelikovArtem/chego"
+)
+
+// Term is a component of the evaluation.
+type Term int
+
+const (
+	Material Term = iota
+	PieceSquare
+	Mobility
+	PawnStructure
+	KingSafety
+	BishopPair
+	// NumTerms is the number of the evaluation terms.
+	NumTerms
+)
+
+// termNames maps each term to its text representation.
+var termNames = [NumTerms]string{
+	"material", "piece-square", "mobility", "pawn-structure", "king-safety",
+	"bishop-pair",
+}
+
+// String returns the name of the term, e.g. "material".
+func (t Term) String() string {
+	if t < 0 || t >= NumTerms {
+		return fmt.Sprintf("Term(%d)", int(t))
+	}
+	return termNames[t]
+}
+
+// Score is a pair of the middlegame and endgame scores in centipawns.
+type Score struct {
+	MG, EG int
+}
+
+// add adds the middlegame and endgame scores.
+func (s *Score) add(mg, eg int) {
+	s.MG += mg
+	s.EG += eg
+}
+
+/*
+Breakdown holds the scores of each evaluation term for each player.  Use it to
+explain the evaluation to the user.
+*/
+type Breakdown struct {
+	// Game phase from 0 (only kings and pawns) to 24 (all pieces on board).
+	Phase int
+	// Scores of each term indexed by the term and the player's color.
+	Terms [NumTerms][2]Score
+}
+
+// taper interpolates the score by the game phase.
+func (b *Breakdown) taper(s Score) int {
+	return (s.MG*b.Phase + s.EG*(maxPhase-b.Phase)) / maxPhase
+}
+
+// Side returns the tapered score of the term for the specified player.
+func (b *Breakdown) Side(t Term, c chego.Color) int {
+	return b.taper(b.Terms[t][c])
+}
+
+/*
+Term returns the tapered score of the term from the white player's side: the
+positive score means the term favours white.
+*/
+func (b *Breakdown) Term(t Term) int {
+	return b.Side(t, chego.ColorWhite) - b.Side(t, chego.ColorBlack)
+}
+
+/*
+Total returns the sum of the tapered terms from the white player's side in
+centipawns.
+*/
+func (b *Breakdown) Total() int {
+	total := 0
+	for t := range NumTerms {
+		total += b.Term(t)
+	}
+	return total
+}
+
+/*
+String formats the breakdown as a table of the tapered term scores, e.g.:
+
+	term            white  black  total
+	material         3911   3911      0
+	...
+	total            4130   4100     30
+*/
+func (b *Breakdown) String() string {
+	var str strings.Builder
+
+	fmt.Fprintf(&str, "%-14s %6s %6s %6s\n", "term", "white", "black", "total")
+	white, black := 0, 0
+	for t := range NumTerms {
+		w, bl := b.Side(t, chego.ColorWhite), b.Side(t, chego.ColorBlack)
+		fmt.Fprintf(&str, "%-14s %6d %6d %6d\n", t, w, bl, w-bl)
+		white += w
+		black += bl
+	}
+	fmt.Fprintf(&str, "%-14s %6d %6d %6d", "total", white, black, white-black)
+
+	return str.String()
+}
+
+/*
+Evaluate returns the score of the position in centipawns from the active
+player's side: the positive score means the active player is better.
+*/
+func Evaluate(p chego.Position) int {
+	b := Explain(p)
+	if p.ActiveColor == chego.ColorBlack {
+		return -b.Total()
+	}
+	return b.Total()
+}
+
+// Explain evaluates the position and returns the scores of each term.
+func Explain(p chego.Position) Breakdown {
+	var b Breakdown
+
+	bb := &p.Bitboards
+	occupancy := chego.Bitboard(bb[14])
+	pawns := [2]chego.Bitboard{
+		chego.Bitboard(bb[chego.PieceWPawn]),
+		chego.Bitboard(bb[chego.PieceBPawn]),
+	}
+	pawnAttacks := [2]chego.Bitboard{
+		pawns[0].Shift(chego.NorthEast) | pawns[0].Shift(chego.NorthWest),
+		pawns[1].Shift(chego.SouthEast) | pawns[1].Shift(chego.SouthWest),
+	}
+
+	// Squares around the kings and the weights of the pieces attacking them.
+	var kingZones [2]chego.Bitboard
+	var attackWeights, attackers [2]int
+	for c := range 2 {
+		if king := chego.Bitboard(bb[chego.PieceWKing+c]); king != 0 {
+			kingZones[c] = chego.KingAttacks(king.LSB()) | king
+		}
+	}
+
+	for piece := chego.PieceWPawn; piece <= chego.PieceBKing; piece++ {
+		c, kind := piece%2, piece/2
+		own := chego.Bitboard(bb[12+c])
+
+		for s := range chego.Bitboard(bb[piece]).Squares() {
+			b.Phase += phaseWeight[kind]
+			b.Terms[Material][c].add(mgValue[kind], egValue[kind])
+
+			i := s
+			if c == chego.ColorWhite {
+				i = s.Mirror()
+			}
+			b.Terms[PieceSquare][c].add(mgTables[kind][i], egTables[kind][i])
+
+			var attacks chego.Bitboard
+			switch kind {
+			case chego.PieceWKnight / 2:
+				attacks = chego.KnightAttacks(s)
+			case chego.PieceWBishop / 2:
+				attacks = chego.BishopAttacks(s, occupancy)
+			case chego.PieceWRook / 2:
+				attacks = chego.RookAttacks(s, occupancy)
+			case chego.PieceWQueen / 2:
+				attacks = chego.QueenAttacks(s, occupancy)
+			default:
+				continue
+			}
+
+			// The squares attacked by the enemy pawns are unsafe.
+			n := (attacks &^ own &^ pawnAttacks[1^c]).Count() - mobilityBaseline[kind]
+			b.Terms[Mobility][c].add(n*mgMobility[kind], n*egMobility[kind])
+
+			if attacks&kingZones[1^c] != 0 {
+				attackWeights[1^c] += kingAttackWeight[kind]
+				attackers[1^c]++
+			}
+		}
+	}
+	b.Phase = min(b.Phase, maxPhase)
+
+	// Pieces in the Crazyhouse pockets are as good as the pieces on board.
+	if p.Variant == chego.VariantCrazyhouse {
+		for piece, n := range p.Pockets {
+			kind := piece / 2
+			b.Terms[Material][piece%2].add(int(n)*mgValue[kind],
+				int(n)*egValue[kind])
+		}
+	}
+
+	for c := range 2 {
+		b.Terms[PawnStructure][c] = pawnStructure(pawns[c], pawns[1^c], c)
+
+		if bishops := chego.Bitboard(bb[chego.PieceWBishop+c]); bishops.Count() >= 2 {
+			b.Terms[BishopPair][c].add(mgBishopPair, egBishopPair)
+		}
+
+		if king := chego.Bitboard(bb[chego.PieceWKing+c]); king != 0 {
+			b.Terms[KingSafety][c] = kingSafety(king.LSB(), pawns[c], c,
+				attackWeights[c], attackers[c])
+		}
+	}
+
+	return b
+}
+
+// fileMask returns the bitboard of the file, or the empty one if it's invalid.
+func fileMask(file int) chego.Bitboard {
+	if file < 0 || file > 7 {
+		return 0
+	}
+	return chego.Bitboard(0x0101010101010101) << file
+}
+
+// forwardRanks returns the ranks in front of the rank from the player's side.
+func forwardRanks(rank int, c chego.Color) chego.Bitboard {
+	switch {
+	case c == chego.ColorWhite:
+		return ^chego.Bitboard(0) << (8 * (rank + 1))
+	case rank <= 0:
+		return 0
+	}
+	return chego.Bitboard(1)<<(8*rank) - 1
+}
+
+/*
+pawnStructure penalizes the doubled and isolated pawns, and rewards the passed
+pawns, which have no enemy pawns in front of them on the same and adjacent
+files, and no own pawns in front of them on the same file.
+*/
+func pawnStructure(own, enemy chego.Bitboard, c chego.Color) (s Score) {
+	for file := range 8 {
+		n := (own & fileMask(file)).Count()
+		if n > 1 {
+			s.add((n-1)*mgDoubled, (n-1)*egDoubled)
+		}
+		if n > 0 && own&(fileMask(file-1)|fileMask(file+1)) == 0 {
+			s.add(n*mgIsolated, n*egIsolated)
+		}
+	}
+
+	for sq := range own.Squares() {
+		files := fileMask(sq.File()-1) | fileMask(sq.File()) | fileMask(sq.File()+1)
+		// The rear doubled pawn is blocked by the front one.
+		front := forwardRanks(sq.Rank(), c)
+		if enemy&files&front != 0 || own&fileMask(sq.File())&front != 0 {
+			continue
+		}
+		rank := sq.Rank()
+		if c == chego.ColorBlack {
+			rank = 7 - rank
+		}
+		s.add(mgPassed[rank], egPassed[rank])
+	}
+
+	return s
+}
+
+/*
+kingSafety rewards the pawn shield in front of the king and penalizes the open
+files next to it and the enemy pieces attacking the squares around it.  The
+king safety matters only in the middlegame.
+*/
+func kingSafety(king chego.Square, pawns chego.Bitboard, c chego.Color,
+	attackWeight, attackers int) (s Score) {
+	files := fileMask(king.File()-1) | fileMask(king.File()) |
+		fileMask(king.File()+1)
+
+	// Two ranks in front of the king.
+	shield := forwardRanks(king.Rank(), c) &^ forwardRanks(king.Rank()+2, c)
+	if c == chego.ColorBlack {
+		shield = forwardRanks(king.Rank(), c) &^ forwardRanks(king.Rank()-2, c)
+	}
+	s.MG += min((pawns&files&shield).Count(), 3) * pawnShield
+
+	for file := king.File() - 1; file <= king.File()+1; file++ {
+		if file >= 0 && file <= 7 && pawns&fileMask(file) == 0 {
+			s.MG += openFileNearKing
+		}
+	}
+
+	// A single attacker is rarely dangerous.
+	if attackers >= 2 {
+		s.MG -= min(attackWeight*attackWeight, maxKingDanger)
+	}
+
+	return s
+}
@@ -0,0 +1,180 @@
+package eval
+
+import (
+	"os"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/BelikovArtem/chego"
+)
+
+func TestMain(m *testing.M) {
+	chego.InitAttackTables()
+	os.Exit(m.Run())
+}
+
+/*
+mirrorFEN swaps the colors of the position: the board is reflected vertically,
+the piece colors and the active color are swapped.
+*/
+func mirrorFEN(fen string) string {
+	fields := strings.Fields(fen)
+
+	ranks := strings.Split(fields[0], "/")
+	slices.Reverse(ranks)
+	board := []byte(strings.Join(ranks, "/"))
+	for i, c := range board {
+		switch {
+		case c >= 'a' && c <= 'z':
+			board[i] = c - 'a' + 'A'
+		case c >= 'A' && c <= 'Z':
+			board[i] = c - 'A' + 'a'
+		}
+	}
+
+	active := "w"
+	if fields[1] == "w" {
+		active = "b"
+	}
+	return string(board) + " " + active + " - - 0 1"
+}
+
+func TestEvaluateSymmetry(t *testing.T) {
+	fens := []string{
+		chego.InitialPos,
+		"rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq - 0 1",
+		"r1bqkbnr/pppp1ppp/2n5/4p3/4P3/5N2/PPPP1PPP/RNBQKB1R w KQkq - 2 3",
+		"r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1",
+		"8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1",
+		"4k3/8/8/8/8/8/4P3/4K3 w - - 0 1",
+	}
+
+	for _, fen := range fens {
+		p := chego.ParseFEN(fen)
+		mirrored := chego.ParseFEN(mirrorFEN(fen))
+
+		if got, expected := Evaluate(mirrored), Evaluate(p); got != expected {
+			t.Fatalf("%s: expected %d got %d for the mirrored position", fen,
+				expected, got)
+		}
+	}
+
+	if got := Evaluate(chego.ParseFEN(chego.InitialPos)); got != 0 {
+		t.Fatalf("expected 0 for the initial position got %d", got)
+	}
+}
+
+func TestEvaluateSign(t *testing.T) {
+	// White is a queen up.
+	white := chego.ParseFEN("rnb1kbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1")
+	black := chego.ParseFEN("rnb1kbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR b KQkq - 0 1")
+
+	if got := Evaluate(white); got < 800 {
+		t.Fatalf("expected a queen advantage got %d", got)
+	}
+	if Evaluate(black) != -Evaluate(white) {
+		t.Fatalf("expected %d got %d", -Evaluate(white), Evaluate(black))
+	}
+}
+
+func TestExplain(t *testing.T) {
+	testcases := []struct {
+		name     string
+		fen      string
+		term     Term
+		expected Score
+		// Color of the player whose score is checked.
+		color chego.Color
+	}{
+		{"doubled isolated", "4k3/8/8/8/8/4P3/4P3/4K3 w - - 0 1", PawnStructure,
+			// Doubled, two isolated pawns, the front one is passed.
+			Score{-10 - 20 + 10, -20 - 30 + 20}, chego.ColorWhite},
+		{"blocked", "4k3/4p3/8/8/8/8/3PPP2/4K3 w - - 0 1", PawnStructure,
+			Score{0, 0}, chego.ColorWhite},
+		{"passed", "4k3/8/8/8/P7/8/8/4K3 w - - 0 1", PawnStructure,
+			// Isolated and passed on the fourth rank.
+			Score{-10 + 15, -15 + 35}, chego.ColorWhite},
+		{"black passed", "4k3/8/8/1p6/8/8/8/4K3 w - - 0 1", PawnStructure,
+			// The fifth rank is the fourth one from the black player's side.
+			Score{-10 + 15, -15 + 35}, chego.ColorBlack},
+		{"bishop pair", "2b1kb2/8/8/8/8/8/8/2B1K3 w - - 0 1", BishopPair,
+			Score{30, 50}, chego.ColorBlack},
+		{"no bishop pair", "2b1kb2/8/8/8/8/8/8/2B1K3 w - - 0 1", BishopPair,
+			Score{0, 0}, chego.ColorWhite},
+		{"pawn shield", "4k3/8/8/8/8/8/5PPP/6K1 w - - 0 1", KingSafety,
+			Score{30, 0}, chego.ColorWhite},
+		{"open king", "4k3/8/8/8/8/8/8/6K1 w - - 0 1", KingSafety,
+			Score{-45, 0}, chego.ColorWhite},
+		{"crazyhouse pocket", "4k3/8/8/8/8/8/8/4K3[N] w - - 0 1", Material,
+			Score{337, 281}, chego.ColorWhite},
+	}
+
+	for _, tc := range testcases {
+		b := Explain(chego.ParseFEN(tc.fen))
+		if got := b.Terms[tc.term][tc.color]; got != tc.expected {
+			t.Fatalf("%s: expected %+v got %+v\n%s", tc.name, tc.expected, got,
+				&b)
+		}
+	}
+}
+
+func TestKingAttack(t *testing.T) {
+	// The queen and the knight attack the castled king.
+	p := chego.ParseFEN("r1b2rk1/ppp2ppp/8/4N2Q/8/8/PPP2PPP/R3K2R w KQ - 0 1")
+	b := Explain(p)
+
+	// Full pawn shield and the attack weight 2+5.
+	expected := 3*pawnShield - 7*7
+	if got := b.Terms[KingSafety][chego.ColorBlack].MG; got != expected {
+		t.Fatalf("expected %d got %d\n%s", expected, got, &b)
+	}
+}
+
+func TestPhase(t *testing.T) {
+	testcases := []struct {
+		fen      string
+		expected int
+	}{
+		{chego.InitialPos, 24},
+		{"4k3/pppppppp/8/8/8/8/PPPPPPPP/4K3 w - - 0 1", 0},
+		{"3qk3/8/8/8/8/8/8/3QK3 w - - 0 1", 8},
+		// Promoted queens don't exceed the maximal phase.
+		{"qqqqkqqq/8/8/8/8/8/8/QQQQKQQQ w - - 0 1", 24},
+	}
+
+	for _, tc := range testcases {
+		if got := Explain(chego.ParseFEN(tc.fen)).Phase; got != tc.expected {
+			t.Fatalf("%s: expected phase %d got %d", tc.fen, tc.expected, got)
+		}
+	}
+}
+
+func TestBreakdownString(t *testing.T) {
+	b := Explain(chego.ParseFEN("4k3/8/8/8/8/8/4P3/4K3 w - - 0 1"))
+
+	got := b.String()
+	for _, line := range []string{
+		"term            white  black  total",
+		"material           94      0     94",
+		"total              59    -30     89",
+	} {
+		if !strings.Contains(got, line) {
+			t.Fatalf("expected %q in\n%s", line, got)
+		}
+	}
+
+	if b.Total() != 89 {
+		t.Fatalf("expected total 89 got %d", b.Total())
+	}
+	if Term(42).String() != "Term(42)" || KingSafety.String() != "king-safety" {
+		t.Fatalf("invalid term names")
+	}
+}
+
+func BenchmarkEvaluate(b *testing.B) {
+	p := chego.ParseFEN("r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1")
+	for b.Loop() {
+		Evaluate(p)
+	}
+}
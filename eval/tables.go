@@ -0,0 +1,156 @@
+/*
+tables.go defines the piece values and the piece-square tables.  The tables are
+written from the white player's side with the eighth rank first, so the square
+of a white piece is mirrored to index them.
+*/
+
+package eval
+
+// Middlegame and endgame values of the pieces indexed by the piece type divided
+// by two.  The king has no material value.
+var (
+	mgValue = [6]int{82, 337, 365, 477, 1025, 0}
+	egValue = [6]int{94, 281, 297, 512, 936, 0}
+)
+
+// Contribution of each piece type to the game phase.
+var phaseWeight = [6]int{0, 1, 1, 2, 4, 0}
+
+// maxPhase is the phase of the starting position.
+const maxPhase = 24
+
+var mgPawnTable = [64]int{
+	0, 0, 0, 0, 0, 0, 0, 0,
+	50, 50, 50, 50, 50, 50, 50, 50,
+	10, 10, 20, 30, 30, 20, 10, 10,
+	5, 5, 10, 25, 25, 10, 5, 5,
+	0, 0, 0, 20, 20, 0, 0, 0,
+	5, -5, -10, 0, 0, -10, -5, 5,
+	5, 10, 10, -20, -20, 10, 10, 5,
+	0, 0, 0, 0, 0, 0, 0, 0,
+}
+
+var egPawnTable = [64]int{
+	0, 0, 0, 0, 0, 0, 0, 0,
+	80, 80, 80, 80, 80, 80, 80, 80,
+	50, 50, 50, 50, 50, 50, 50, 50,
+	30, 30, 30, 30, 30, 30, 30, 30,
+	15, 15, 15, 15, 15, 15, 15, 15,
+	5, 5, 5, 5, 5, 5, 5, 5,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+}
+
+var knightTable = [64]int{
+	-50, -40, -30, -30, -30, -30, -40, -50,
+	-40, -20, 0, 0, 0, 0, -20, -40,
+	-30, 0, 10, 15, 15, 10, 0, -30,
+	-30, 5, 15, 20, 20, 15, 5, -30,
+	-30, 0, 15, 20, 20, 15, 0, -30,
+	-30, 5, 10, 15, 15, 10, 5, -30,
+	-40, -20, 0, 5, 5, 0, -20, -40,
+	-50, -40, -30, -30, -30, -30, -40, -50,
+}
+
+var bishopTable = [64]int{
+	-20, -10, -10, -10, -10, -10, -10, -20,
+	-10, 0, 0, 0, 0, 0, 0, -10,
+	-10, 0, 5, 10, 10, 5, 0, -10,
+	-10, 5, 5, 10, 10, 5, 5, -10,
+	-10, 0, 10, 10, 10, 10, 0, -10,
+	-10, 10, 10, 10, 10, 10, 10, -10,
+	-10, 5, 0, 0, 0, 0, 5, -10,
+	-20, -10, -10, -10, -10, -10, -10, -20,
+}
+
+var mgRookTable = [64]int{
+	0, 0, 0, 0, 0, 0, 0, 0,
+	5, 10, 10, 10, 10, 10, 10, 5,
+	-5, 0, 0, 0, 0, 0, 0, -5,
+	-5, 0, 0, 0, 0, 0, 0, -5,
+	-5, 0, 0, 0, 0, 0, 0, -5,
+	-5, 0, 0, 0, 0, 0, 0, -5,
+	-5, 0, 0, 0, 0, 0, 0, -5,
+	0, 0, 0, 5, 5, 0, 0, 0,
+}
+
+// The rooks are equally good everywhere in the endgame.
+var egRookTable = [64]int{}
+
+var queenTable = [64]int{
+	-20, -10, -10, -5, -5, -10, -10, -20,
+	-10, 0, 0, 0, 0, 0, 0, -10,
+	-10, 0, 5, 5, 5, 5, 0, -10,
+	-5, 0, 5, 5, 5, 5, 0, -5,
+	0, 0, 5, 5, 5, 5, 0, -5,
+	-10, 5, 5, 5, 5, 5, 0, -10,
+	-10, 0, 5, 0, 0, 0, 0, -10,
+	-20, -10, -10, -5, -5, -10, -10, -20,
+}
+
+// The king hides behind the pawns in the middlegame.
+var mgKingTable = [64]int{
+	-30, -40, -40, -50, -50, -40, -40, -30,
+	-30, -40, -40, -50, -50, -40, -40, -30,
+	-30, -40, -40, -50, -50, -40, -40, -30,
+	-30, -40, -40, -50, -50, -40, -40, -30,
+	-20, -30, -30, -40, -40, -30, -30, -20,
+	-10, -20, -20, -20, -20, -20, -20, -10,
+	20, 20, 0, 0, 0, 0, 20, 20,
+	20, 30, 10, 0, 0, 10, 30, 20,
+}
+
+// The king walks to the center in the endgame.
+var egKingTable = [64]int{
+	-50, -40, -30, -20, -20, -30, -40, -50,
+	-30, -20, -10, 0, 0, -10, -20, -30,
+	-30, -10, 20, 30, 30, 20, -10, -30,
+	-30, -10, 30, 40, 40, 30, -10, -30,
+	-30, -10, 30, 40, 40, 30, -10, -30,
+	-30, -10, 20, 30, 30, 20, -10, -30,
+	-30, -30, 0, 0, 0, 0, -30, -30,
+	-50, -30, -30, -30, -30, -30, -30, -50,
+}
+
+// Middlegame and endgame piece-square tables indexed by the piece type divided
+// by two.
+var (
+	mgTables = [6]*[64]int{&mgPawnTable, &knightTable, &bishopTable,
+		&mgRookTable, &queenTable, &mgKingTable}
+	egTables = [6]*[64]int{&egPawnTable, &knightTable, &bishopTable,
+		&egRookTable, &queenTable, &egKingTable}
+)
+
+/*
+Mobility weights and the baseline number of the attacked squares indexed by the
+piece type divided by two.  A piece attacking fewer squares than the baseline
+is penalized.
+*/
+var (
+	mgMobility       = [6]int{0, 4, 5, 2, 1, 0}
+	egMobility       = [6]int{0, 4, 5, 4, 2, 0}
+	mobilityBaseline = [6]int{0, 4, 6, 7, 13, 0}
+)
+
+// Bonuses of the passed pawns indexed by the relative rank.
+var (
+	mgPassed = [8]int{0, 5, 10, 15, 25, 40, 60, 0}
+	egPassed = [8]int{0, 10, 20, 35, 60, 90, 130, 0}
+)
+
+// Pawn structure and king safety weights.
+const (
+	mgDoubled        = -10
+	egDoubled        = -20
+	mgIsolated       = -10
+	egIsolated       = -15
+	mgBishopPair     = 30
+	egBishopPair     = 50
+	pawnShield       = 10
+	openFileNearKing = -15
+	maxKingDanger    = 500
+)
+
+// Weights of the pieces attacking the king zone indexed by the piece type
+// divided by two.
+var kingAttackWeight = [6]int{0, 2, 2, 3, 5, 0}
@@ -5,6 +5,8 @@ bitboard arrays to be valid, and may panic if they are not.
 
 Each FEN string consists of six parts, separated by a space:
  1. Piece placement: will be parsed into the array of bitboards.
+	Crazyhouse positions additionally list the pocket pieces in brackets,
+	e.g. "[Qnp]", and mark the promoted pieces with '~'.
  2. Active color:
 	"w" means that White is to move;
 	"b" means that Black is to move.
@@ -14,6 +16,9 @@ Each FEN string consists of six parts, separated by a space:
 	this field uses the character "-".
  5. Halfmove clock: used for the fifty-move rule.
  6. Fullmove number: The number of the full moves.
+
+Three-check positions have the seventh part: the number of checks delivered by
+white and black, e.g. "+1+0".
 */
 
 package chego
@@ -27,13 +32,36 @@ import (
 /*
 ParseFEN parses the given FEN string into a [Position].  It's a caller's
 responsibility to validate the provided FEN string.
+
+Castling rights may be specified in standard, X-FEN, or Shredder-FEN format.
+The position is considered to be a Chess960 position if the castling rights
+are written as the rook files or if the castling rook doesn't stand in the
+corner.  Use [ParseChess960FEN] to parse Chess960 positions with the rooks
+standing in the corners.
+
+The position is considered to be a Crazyhouse position if the piece placement
+contains the pockets, either in brackets or as the ninth rank, and a Three-check
+position if the number of checks is specified.  Use [ParseVariantFEN] to parse
+positions of other variants.
 */
 func ParseFEN(fen string) (p Position) {
 	// Separate FEN fields.
-	fields := strings.SplitN(fen, " ", 6)
-
-	// Parse piece placement.
-	p.Bitboards = ParseBitboards(fields[0])
+	fields := strings.SplitN(fen, " ", 7)
+
+	// Parse piece placement.  Crazyhouse pockets are written either in
+	// brackets after the placement or as the ninth rank.
+	placement, pockets, isCrazyhouse := strings.Cut(fields[0], "[")
+	if !isCrazyhouse && strings.Count(placement, "/") == 8 {
+		i := strings.LastIndexByte(placement, '/')
+		placement, pockets, isCrazyhouse = placement[:i], placement[i+1:], true
+	}
+	bitboards, promoted := parsePlacement(placement)
+	p.Bitboards = bitboards
+	if isCrazyhouse {
+		p.Variant = VariantCrazyhouse
+		p.Pockets = parsePockets(strings.TrimSuffix(pockets, "]"))
+		p.Promoted = promoted
+	}
 
 	// Parse active color.
 	// p will have ColorWhite by default.
@@ -42,18 +70,7 @@ func ParseFEN(fen string) (p Position) {
 	}
 
 	// Parse castling rights.
-	for i := range len(fields[2]) {
-		switch fields[2][i] {
-		case 'K':
-			p.CastlingRights |= CastlingWhiteShort
-		case 'Q':
-			p.CastlingRights |= CastlingWhiteLong
-		case 'k':
-			p.CastlingRights |= CastlingBlackShort
-		case 'q':
-			p.CastlingRights |= CastlingBlackLong
-		}
-	}
+	parseCastlingRights(&p, fields[2])
 
 	// Parse en passant target square.
 	p.EPTarget = string2Square(fields[3])
@@ -71,16 +88,67 @@ func ParseFEN(fen string) (p Position) {
 		panic("cannot parse fullmove counter from FEN string")
 	}
 
+	// Parse Three-check counters.
+	if len(fields) == 7 {
+		p.Variant = VariantThreeCheck
+		p.Checks = parseChecks(fields[6])
+	}
+
+	return p
+}
+
+/*
+ParseVariantFEN parses the given FEN string into a [Position] of the specified
+variant kind.  Use it for the variants which cannot be detected from the FEN
+string, such as King of the Hill.
+*/
+func ParseVariantFEN(fen string, kind VariantKind) Position {
+	p := ParseFEN(fen)
+	p.Variant = kind
+	return p
+}
+
+/*
+ParseChess960FEN parses the given FEN string into a Chess960 [Position] even
+if the king and the castling rooks stand on their standard squares.
+*/
+func ParseChess960FEN(fen string) Position {
+	p := ParseFEN(fen)
+	p.enableChess960()
 	return p
 }
 
-// SerializeFEN serializes the specified [Position] into a FEN string.
+/*
+SerializeFEN serializes the specified [Position] into a FEN string.  Castling
+rights of Chess960 positions are serialized in X-FEN format: the file of the
+castling rook is written instead of K, Q, k or q only if there is another rook
+between the castling rook and the corner.
+*/
 func SerializeFEN(p Position) string {
+	return serializeFEN(p, false)
+}
+
+/*
+SerializeShredderFEN serializes the specified [Position] into a Shredder-FEN
+string, where the castling rights are always written as the files of the
+castling rooks, e.g. "HAha" instead of "KQkq".
+*/
+func SerializeShredderFEN(p Position) string {
+	return serializeFEN(p, true)
+}
+
+// serializeFEN serializes the position into a FEN or Shredder-FEN string.
+func serializeFEN(p Position, shredder bool) string {
 	var fen strings.Builder
 	fen.Grow(64)
 
 	// 1 field: piece placement.
-	fen.WriteString(SerializeBitboards(p.Bitboards))
+	if p.Variant == VariantCrazyhouse {
+		fen.WriteString(serializePlacement(p.Bitboards, p.Promoted))
+		fen.WriteString(serializePockets(p.Pockets))
+	} else {
+		fen.WriteString(SerializeBitboards(p.Bitboards))
+	}
 
 	// 2 field: active color.
 	if p.ActiveColor == ColorWhite {
@@ -90,26 +158,7 @@ func SerializeFEN(p Position) string {
 	}
 
 	// 3 field: castling rights.
-	cnt := 4
-	if p.CastlingRights&CastlingWhiteShort != 0 {
-		fen.WriteByte('K')
-		cnt--
-	}
-	if p.CastlingRights&CastlingWhiteLong != 0 {
-		fen.WriteByte('Q')
-		cnt--
-	}
-	if p.CastlingRights&CastlingBlackShort != 0 {
-		fen.WriteByte('k')
-		cnt--
-	}
-	if p.CastlingRights&CastlingBlackLong != 0 {
-		fen.WriteByte('q')
-		cnt--
-	}
-	if cnt == 4 {
-		fen.WriteByte('-')
-	}
+	fen.WriteString(serializeCastlingRights(p, shredder))
 	fen.WriteByte(' ')
 
 	// 4 field: en passant target square.
@@ -129,6 +178,14 @@ func SerializeFEN(p Position) string {
 	// 6 field: the number of fullmoves.
 	fen.WriteString(strconv.Itoa(p.FullmoveCnt))
 
+	// 7 field: the number of checks in Three-check.
+	if p.Variant == VariantThreeCheck {
+		fen.WriteString(" +")
+		fen.WriteString(strconv.Itoa(int(p.Checks[ColorWhite])))
+		fen.WriteByte('+')
+		fen.WriteString(strconv.Itoa(int(p.Checks[ColorBlack])))
+	}
+
 	return fen.String()
 }
 
@@ -137,6 +194,16 @@ ParseBitboards converts the first part of a FEN string into an array of
 bitboards.  May panic if the provided string is not valid.
 */
 func ParseBitboards(piecePlacement string) (bitboards [15]uint64) {
+	bitboards, _ = parsePlacement(piecePlacement)
+	return bitboards
+}
+
+/*
+parsePlacement converts the first part of a FEN string into an array of
+bitboards and a bitboard of promoted pieces, which are marked with '~' in
+Crazyhouse.
+*/
+func parsePlacement(piecePlacement string) (bitboards [15]uint64, promoted uint64) {
 	square := 56
 
 	// Piece placement data describes each rank beginning from the eigth.
@@ -149,6 +216,8 @@ func ParseBitboards(piecePlacement string) (bitboards [15]uint64) {
 		} else if char >= '1' && char <= '8' {
 			// Convert byte to the integer it represents.
 			square += int(char - '0')
+		} else if char == '~' { // The previous piece is promoted.
+			promoted |= 1 << (square - 1)
 		} else { // There is piece on a square.
 			piece := PieceWPawn
 			// Manual switch construction is ~3x faster than map approach.
@@ -191,7 +260,7 @@ func ParseBitboards(piecePlacement string) (bitboards [15]uint64) {
 		}
 	}
 
-	return bitboards
+	return bitboards, promoted
 }
 
 /*
@@ -199,6 +268,14 @@ SerializeBitboards converts the array of bitboards into the first part of FEN
 string.
 */
 func SerializeBitboards(bitboards [15]uint64) string {
+	return serializePlacement(bitboards, 0)
+}
+
+/*
+serializePlacement converts the array of bitboards into the first part of FEN
+string marking the promoted pieces with '~'.
+*/
+func serializePlacement(bitboards [15]uint64, promoted uint64) string {
 	// Used to add characters to a string without extra memory allocations.
 	b := strings.Builder{}
 	b.Grow(30)
@@ -226,6 +303,9 @@ func SerializeBitboards(bitboards [15]uint64) string {
 					emptySquares = 0
 				}
 				b.WriteByte(board[square])
+				if promoted&(1<<square) != 0 {
+					b.WriteByte('~')
+				}
 			}
 
 			// To add rank separators.
@@ -245,6 +325,177 @@ func SerializeBitboards(bitboards [15]uint64) string {
 	return b.String()
 }
 
+// pocketSymbols lists the pieces in the order they are written in FEN pockets.
+var pocketSymbols = [10]Piece{
+	PieceWQueen, PieceWRook, PieceWBishop, PieceWKnight, PieceWPawn,
+	PieceBQueen, PieceBRook, PieceBBishop, PieceBKnight, PieceBPawn,
+}
+
+/*
+parsePockets parses the Crazyhouse pocket pieces, e.g. "QNPnpp", into the
+number of pieces of each type.
+*/
+func parsePockets(str string) (pockets [10]uint8) {
+	for i := range len(str) {
+		for _, piece := range pocketSymbols {
+			if PieceSymbols[piece] == str[i] {
+				pockets[piece]++
+			}
+		}
+	}
+	return pockets
+}
+
+/*
+serializePockets serializes the Crazyhouse pocket pieces in brackets, white
+pieces first, e.g. "[QNPnpp]".  Empty pockets are serialized as "[]".
+*/
+func serializePockets(pockets [10]uint8) string {
+	b := make([]byte, 0, 16)
+	b = append(b, '[')
+	for _, piece := range pocketSymbols {
+		for range pockets[piece] {
+			b = append(b, PieceSymbols[piece])
+		}
+	}
+	return string(append(b, ']'))
+}
+
+/*
+parseChecks parses the number of checks delivered by each player in Three-check,
+e.g. "+1+0".
+*/
+func parseChecks(field string) (checks [2]uint8) {
+	white, black, _ := strings.Cut(strings.TrimPrefix(field, "+"), "+")
+
+	for c, str := range [2]string{white, black} {
+		n, err := strconv.Atoi(str)
+		if err != nil {
+			panic("cannot parse number of checks from FEN string")
+		}
+		checks[c] = uint8(n)
+	}
+
+	return checks
+}
+
+/*
+parseCastlingRights parses the castling rights field of a FEN string into the
+position.  The piece placement must already be parsed.
+*/
+func parseCastlingRights(p *Position, field string) {
+	for i := range len(field) {
+		char := field[i]
+
+		c := ColorWhite
+		if char >= 'a' && char <= 'z' {
+			c = ColorBlack
+			char -= 'a' - 'A'
+		}
+
+		rank := 56 * c
+		king := bitScan(p.Bitboards[PieceWKing+c])
+		rooks := p.Bitboards[PieceWRook+c] & (RANK_1 << rank)
+
+		rook := -1
+		switch {
+		case char == 'K': // Outermost rook on the king side.
+			for r := rank + 7; r > king; r-- {
+				if rooks&(1<<r) != 0 {
+					rook = r
+					break
+				}
+			}
+		case char == 'Q': // Outermost rook on the queen side.
+			for r := rank; r < king; r++ {
+				if rooks&(1<<r) != 0 {
+					rook = r
+					break
+				}
+			}
+		case char >= 'A' && char <= 'H': // Shredder-FEN rook file.
+			rook = rank + int(char-'A')
+			p.Chess960 = true
+		default: // No castling rights.
+			continue
+		}
+
+		// Index of the castling rights bit.
+		i := 2 * c
+		if char == 'Q' || (char != 'K' && rook < king) {
+			i++
+		}
+		p.CastlingRights |= 1 << i
+
+		// Keep the right even if there is no rook to castle with.
+		if rook == -1 {
+			p.CastlingRooks[i] = standardCastlingRooks[i]
+			continue
+		}
+
+		p.CastlingRooks[i] = rook
+		if rook != standardCastlingRooks[i] {
+			p.Chess960 = true
+		}
+	}
+
+	if !p.Chess960 {
+		// Standard positions do not use castling rook squares.
+		p.CastlingRooks = [4]int{}
+	}
+}
+
+/*
+serializeCastlingRights serializes the castling rights into the FEN field.
+See [SerializeFEN] and [SerializeShredderFEN] for the format details.
+*/
+func serializeCastlingRights(p Position, shredder bool) string {
+	if p.CastlingRights == 0 {
+		return "-"
+	}
+
+	symbols := "KQkq"
+	b := make([]byte, 0, 4)
+
+	for i := range 4 {
+		if p.CastlingRights&(1<<i) == 0 {
+			continue
+		}
+
+		if !p.Chess960 && !shredder {
+			b = append(b, symbols[i])
+			continue
+		}
+
+		rook := p.castlingRook(i)
+		file := byte('A' + rook%8)
+		if i >= 2 {
+			file = byte('a' + rook%8)
+		}
+
+		if shredder {
+			b = append(b, file)
+			continue
+		}
+
+		// X-FEN uses the file only if another rook stands between the
+		// castling rook and the corner.
+		rank := rook &^ 7
+		outer := rankSpan(rook, rank+7) &^ (1 << rook)
+		if i%2 == 1 {
+			outer = rankSpan(rook, rank) &^ (1 << rook)
+		}
+
+		if p.Bitboards[PieceWRook+i/2]&outer != 0 {
+			b = append(b, file)
+		} else {
+			b = append(b, symbols[i])
+		}
+	}
+
+	return string(b)
+}
+
 /*
 string2Square parses the given string into a square index. Handles "-" as A1 square.
 */
@@ -134,6 +134,12 @@ func TestSerializeFEN(t *testing.T) {
 			ActiveColor: ColorWhite, CastlingRights: 0x0,
 			EPTarget: 0x0, HalfmoveCnt: 0, FullmoveCnt: 64,
 		}, "4k3/8/8/8/8/3P4/2K5/8 w - - 0 64"},
+		{Position{
+			Bitboards:   ParseBitboards("4k3/8/8/8/8/3P4/2K5/8"),
+			ActiveColor: ColorWhite, CastlingRights: 0x0,
+			EPTarget: 0x0, HalfmoveCnt: 0, FullmoveCnt: 64,
+			Variant: VariantThreeCheck, Checks: [2]uint8{2, 1},
+		}, "4k3/8/8/8/8/3P4/2K5/8 w - - 0 64 +2+1"},
 	}
 
 	for _, tc := range testcases {
@@ -181,3 +187,21 @@ func BenchmarkSerializeFEN(b *testing.B) {
 		})
 	}
 }
+
+func TestParseThreeCheckFEN(t *testing.T) {
+	fen := "rnbqkbnr/ppp2ppp/8/3pp3/4P3/8/PPPP1PPP/RNBQKBNR w KQkq - 0 3 +1+0"
+
+	p := ParseFEN(fen)
+	if p.Variant != VariantThreeCheck || p.Checks != [2]uint8{1, 0} {
+		t.Fatalf("expected Three-check position with +1+0 checks got %v %v",
+			p.Variant, p.Checks)
+	}
+	if got := SerializeFEN(p); got != fen {
+		t.Fatalf("expected %s got %s", fen, got)
+	}
+
+	p = ParseVariantFEN(InitialPos, VariantKingOfTheHill)
+	if p.Variant != VariantKingOfTheHill || SerializeFEN(p) != InitialPos {
+		t.Fatalf("expected King of the Hill position")
+	}
+}
@@ -0,0 +1,199 @@
+/*
+format.go implements the human-readable text representation of the position,
+used for debugging and in the test failure messages.
+*/
+
+package chego
+
+import (
+	"strings"
+)
+
+// FormatOptions configures the text representation of the position.
+type FormatOptions struct {
+	// Print the pieces as Unicode chess glyphs instead of the FEN letters.
+	Unicode bool
+	// Print the board from the black player's side.
+	Flipped bool
+	// Print the rank numbers and the file letters.
+	Coordinates bool
+	// Color the squares and the pieces with the ANSI escape codes.
+	Color bool
+}
+
+// pieceGlyphs maps each piece type to its Unicode chess glyph.
+var pieceGlyphs = [12]string{
+	"♙", "♟", "♘", "♞", "♗", "♝", "♖", "♜", "♕", "♛", "♔", "♚",
+}
+
+// ANSI escape codes of the square backgrounds and the piece colors.
+const (
+	ansiLight = "\x1b[48;5;180m"
+	ansiDark  = "\x1b[48;5;137m"
+	ansiWhite = "\x1b[97m"
+	ansiBlack = "\x1b[30m"
+	ansiReset = "\x1b[0m"
+)
+
+/*
+String returns the ASCII board with the coordinates followed by the active
+color, en passant target and castling rights, e.g.:
+
+	8  r  n  b  q  k  b  n  r
+	7  p  p  p  p  p  p  p  p
+	6  .  .  .  .  .  .  .  .
+	5  .  .  .  .  .  .  .  .
+	4  .  .  .  .  P  .  .  .
+	3  .  .  .  .  .  .  .  .
+	2  P  P  P  P  .  P  P  P
+	1  R  N  B  Q  K  B  N  R
+	   a  b  c  d  e  f  g  h
+	Active color: black
+	En passant: e3
+	Castling rights: KQkq
+*/
+func (p Position) String() string {
+	return p.Format(FormatOptions{Coordinates: true})
+}
+
+/*
+Format returns the text representation of the position configured by the
+options.  See [Position.String] for the layout.  The pockets and the delivered
+checks are printed for the Crazyhouse and Three-check positions.
+*/
+func (p Position) Format(opts FormatOptions) string {
+	var b strings.Builder
+
+	for row := range 8 {
+		rank := 7 - row
+		if opts.Flipped {
+			rank = row
+		}
+
+		if opts.Coordinates {
+			b.WriteByte(byte('1' + rank))
+			b.WriteString("  ")
+		}
+
+		for col := range 8 {
+			file := col
+			if opts.Flipped {
+				file = 7 - col
+			}
+			if col > 0 && !opts.Color {
+				b.WriteString("  ")
+			}
+			p.writeSquare(&b, NewSquare(file, rank), opts)
+		}
+		b.WriteByte('\n')
+	}
+
+	if opts.Coordinates {
+		b.WriteString("   ")
+		for col := range 8 {
+			file := byte('a' + col)
+			if opts.Flipped {
+				file = byte('h' - col)
+			}
+			// Align the letters with the pieces.
+			switch {
+			case opts.Color:
+				b.WriteByte(' ')
+				b.WriteByte(file)
+				b.WriteByte(' ')
+			case col > 0:
+				b.WriteString("  ")
+				fallthrough
+			default:
+				b.WriteByte(file)
+			}
+		}
+		b.WriteByte('\n')
+	}
+
+	b.WriteString("Active color: ")
+	if p.ActiveColor == ColorWhite {
+		b.WriteString("white")
+	} else {
+		b.WriteString("black")
+	}
+
+	b.WriteString("\nEn passant: ")
+	if p.EPTarget == 0 {
+		b.WriteString("none")
+	} else {
+		b.WriteString(Square2String[p.EPTarget])
+	}
+
+	b.WriteString("\nCastling rights: ")
+	if p.CastlingRights == 0 {
+		b.WriteString("none")
+	}
+	for i, symbol := range "KQkq" {
+		if p.CastlingRights&(1<<i) != 0 {
+			b.WriteRune(symbol)
+		}
+	}
+
+	switch p.Variant {
+	case VariantCrazyhouse:
+		b.WriteString("\nPockets: ")
+		empty := true
+		for _, c := range [2]Color{ColorWhite, ColorBlack} {
+			for piece := PieceWPawn + c; piece < PieceWKing; piece += 2 {
+				for range p.Pockets[piece] {
+					b.WriteByte(PieceSymbols[piece])
+					empty = false
+				}
+			}
+		}
+		if empty {
+			b.WriteString("none")
+		}
+
+	case VariantThreeCheck:
+		b.WriteString("\nChecks: white ")
+		b.WriteByte('0' + p.Checks[ColorWhite])
+		b.WriteString(", black ")
+		b.WriteByte('0' + p.Checks[ColorBlack])
+	}
+
+	return b.String()
+}
+
+// writeSquare writes a single square of the board.
+func (p *Position) writeSquare(b *strings.Builder, s Square, opts FormatOptions) {
+	piece := p.GetPieceFromSquare(uint64(s.Bitboard()))
+
+	var symbol string
+	switch {
+	case piece == PieceNone && opts.Color:
+		symbol = " "
+	case piece == PieceNone:
+		symbol = "."
+	case opts.Unicode:
+		symbol = pieceGlyphs[piece]
+	default:
+		symbol = string(PieceSymbols[piece])
+	}
+
+	if !opts.Color {
+		b.WriteString(symbol)
+		return
+	}
+
+	if (s.File()+s.Rank())%2 == 0 {
+		b.WriteString(ansiDark)
+	} else {
+		b.WriteString(ansiLight)
+	}
+	if piece != PieceNone && piece%2 == ColorWhite {
+		b.WriteString(ansiWhite)
+	} else {
+		b.WriteString(ansiBlack)
+	}
+	b.WriteByte(' ')
+	b.WriteString(symbol)
+	b.WriteByte(' ')
+	b.WriteString(ansiReset)
+}
@@ -0,0 +1,101 @@
+package chego
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPositionString(t *testing.T) {
+	expected := `8  r  n  b  q  k  b  n  r
+7  p  p  p  p  p  p  p  p
+6  .  .  .  .  .  .  .  .
+5  .  .  .  .  .  .  .  .
+4  .  .  .  .  P  .  .  .
+3  .  .  .  .  .  .  .  .
+2  P  P  P  P  .  P  P  P
+1  R  N  B  Q  K  B  N  R
+   a  b  c  d  e  f  g  h
+Active color: black
+En passant: e3
+Castling rights: KQkq`
+
+	p := ParseFEN("rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1")
+	if got := p.String(); got != expected {
+		t.Fatalf("expected\n%s\ngot\n%s", expected, got)
+	}
+}
+
+// emptyRank is the empty rank printed without the coordinates.
+const emptyRank = ".  .  .  .  .  .  .  .\n"
+
+func TestFormat(t *testing.T) {
+	testcases := []struct {
+		name     string
+		p        Position
+		opts     FormatOptions
+		expected string
+	}{
+		{
+			"no coordinates",
+			ParseFEN("4k3/8/8/8/8/8/8/R3K3 w Q - 0 1"),
+			FormatOptions{},
+			".  .  .  .  k  .  .  .\n" + strings.Repeat(emptyRank, 6) +
+				"R  .  .  .  K  .  .  .\n" +
+				"Active color: white\nEn passant: none\n" +
+				"Castling rights: Q",
+		},
+		{
+			"flipped unicode",
+			ParseFEN("4k3/8/8/8/8/8/8/R3K3 b - - 0 1"),
+			FormatOptions{Unicode: true, Flipped: true, Coordinates: true},
+			"1  .  .  .  ♔  .  .  .  ♖\n" +
+				"2  .  .  .  .  .  .  .  .\n3  .  .  .  .  .  .  .  .\n" +
+				"4  .  .  .  .  .  .  .  .\n5  .  .  .  .  .  .  .  .\n" +
+				"6  .  .  .  .  .  .  .  .\n7  .  .  .  .  .  .  .  .\n" +
+				"8  .  .  .  ♚  .  .  .  .\n   h  g  f  e  d  c  b  a\n" +
+				"Active color: black\nEn passant: none\nCastling rights: none",
+		},
+		{
+			"crazyhouse",
+			ParseFEN("4k3/8/8/8/8/8/8/4K3[PNq] w - - 0 1"),
+			FormatOptions{},
+			".  .  .  .  k  .  .  .\n" + strings.Repeat(emptyRank, 6) +
+				".  .  .  .  K  .  .  .\n" +
+				"Active color: white\nEn passant: none\n" +
+				"Castling rights: none\nPockets: PNq",
+		},
+		{
+			"three-check",
+			ParseFEN("4k3/8/8/8/8/8/8/4K3 w - - 0 1 +2+0"),
+			FormatOptions{},
+			".  .  .  .  k  .  .  .\n" + strings.Repeat(emptyRank, 6) +
+				".  .  .  .  K  .  .  .\n" +
+				"Active color: white\nEn passant: none\n" +
+				"Castling rights: none\nChecks: white 2, black 0",
+		},
+	}
+
+	for _, tc := range testcases {
+		if got := tc.p.Format(tc.opts); got != tc.expected {
+			t.Fatalf("%s: expected\n%s\ngot\n%s", tc.name, tc.expected, got)
+		}
+	}
+}
+
+func TestFormatColor(t *testing.T) {
+	p := ParseFEN(InitialPos)
+	got := p.Format(FormatOptions{Color: true, Coordinates: true})
+
+	// The a1 square is dark and the white rook is painted white.
+	a1 := "1  " + ansiDark + ansiWhite + " R " + ansiReset
+	if !strings.Contains(got, a1) {
+		t.Fatalf("expected %q in\n%s", a1, got)
+	}
+	// Empty squares are blank.
+	if strings.Contains(got, ".") {
+		t.Fatalf("unexpected empty square symbol in\n%s", got)
+	}
+	if !strings.Contains(got, "\n    a  b  c  d  e  f  g  h \n") {
+		t.Fatalf("misaligned file letters in\n%s", got)
+	}
+}
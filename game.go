@@ -2,7 +2,11 @@
 
 package chego
 
-import "time"
+import (
+	"fmt"
+	"iter"
+	"time"
+)
 
 /*
 Game represents a single chess game state.
@@ -11,9 +15,14 @@ Make sure to call [InitAttackTables] and [InitZobristKeys] ONCE before creating
 a [Game].
 */
 type Game struct {
+	// Rules of the game.  [Standard] by default.
+	Variant    Variant
 	LegalMoves MoveList
 	Position   Position
-	MoveStack  []CompletedMove
+	// Position the game started from.  Used to restore the game state after
+	// all completed moves are popped.
+	StartPosition Position
+	MoveStack     []CompletedMove
 	// Keep track of all captured pieces.
 	Captured []Piece
 	// Keep track of all repeated Zobrist keys to detect
@@ -28,27 +37,55 @@ type Game struct {
 	// Clock will send a tick signal every second.  By default the Clock is
 	// stopped.  The caller should call SetClock to apply the time limit
 	// for a game.
-	Clock  *time.Ticker
-	Result Result
+	Clock *time.Ticker
+	// Moment the clock of the active player was started.  Zero if the Clock
+	// is stopped.
+	TurnStart time.Time
+	// Pending draw offers indexed by color.  See [Game.OfferDraw].
+	DrawOffers [2]bool
+	Result     Result
+	// Winner of the game, or ColorBoth for a draw.  Meaningful only if the
+	// Result is set.
+	Winner Color
 }
 
 // CompletedMove represents a completed move.
 type CompletedMove struct {
 	// Board state after completing the move to enable move undo and
 	// state restoration.
-	FenString string
+	FenString string `json:"fen"`
 	// Move itself.
-	Move Move
+	Move Move `json:"move"`
 	// Remaining time on a player's clock in seconds.
-	TimeLeft int
+	TimeLeft int `json:"timeLeft"`
 }
 
 /*
-NewGame creates a new game initialized with the default chess position.
-Generates legal moves.
+NewGame creates a new game of the specified variant initialized with the
+starting position of the variant.  If no variant is specified, the game is
+played by the [Standard] rules.  Generates legal moves.
 */
-func NewGame() *Game {
+func NewGame(variant ...Variant) *Game {
+	v := optionalVariant(variant)
+	return newGame(v.ParseFEN(v.StartFEN()), v)
+}
+
+/*
+NewChess960Game creates a new game initialized with the Chess960 starting
+position with the specified index from 0 to 959 (see [Chess960FEN]).
+Generates legal moves.  Panics if the index is out of range.
+*/
+func NewChess960Game(index int) *Game {
+	return newGame(Chess960Position(index), Standard{})
+}
+
+/*
+newGame creates a new game of the specified variant initialized with the
+specified position.
+*/
+func newGame(p Position, v Variant) *Game {
 	g := &Game{
+		Variant:     v,
 		MoveStack:   make([]CompletedMove, 0, 15),
 		Repetitions: make(map[uint64]int),
 		Captured:    make([]Piece, 0, 15),
@@ -57,25 +94,83 @@ func NewGame() *Game {
 
 	g.Clock.Stop()
 
-	g.Position = ParseFEN(InitialPos)
+	g.Position = p
+	g.StartPosition = p
 
-	GenLegalMoves(g.Position, &g.LegalMoves)
+	g.Variant.GenLegalMoves(g.Position, &g.LegalMoves)
 
 	// Add initial repetition key.
-	g.Repetitions[zobristKey(g.Position)]++
+	g.Repetitions[ZobristKey(g.Position)]++
 	return g
 }
 
+/*
+resolveVariant returns v, or the built-in variant with the specified name if v
+is nil.  An empty name stands for [Standard].  Returns an error if the name
+doesn't match v.
+*/
+func resolveVariant(v Variant, name string) (Variant, error) {
+	switch {
+	case v == nil && name == "":
+		return Standard{}, nil
+	case v == nil:
+		return ParseVariant(name)
+	case name != "" && name != v.Name():
+		return nil, fmt.Errorf("expected %s game got %s", v.Name(), name)
+	}
+	return v, nil
+}
+
+/*
+replayGame creates a new game of the specified variant starting from the
+position described by the FEN string, or from the starting position of the
+variant if the FEN string is empty, and plays the moves in UCI notation.
+Returns an error if the FEN string or one of the moves is invalid.
+*/
+func replayGame(v Variant, fen string, chess960 bool, moves []string) (*Game,
+	error) {
+	if fen == "" {
+		fen = v.StartFEN()
+	}
+	start, err := tryParseFEN(v.ParseFEN, fen)
+	if err != nil {
+		return nil, err
+	}
+	if chess960 {
+		start.enableChess960()
+	}
+
+	g := newGame(start, v)
+	for i, uci := range moves {
+		m, err := v.UCI2Move(g.Position, &g.LegalMoves, uci)
+		if err != nil {
+			return nil, fmt.Errorf("ply %d: %w", i+1, err)
+		}
+		g.PushMove(m)
+	}
+	return g, nil
+}
+
 /*
 PushMove updates the game state by performing the specified move.  It is a
 caller responsibility to check if the specified move is legal.  Generates
 legal moves for the next turn.
 */
 func (g *Game) PushMove(m Move) {
+	// Making a move declines the opponent's draw offer.
+	g.DrawOffers[1^g.Position.ActiveColor] = false
+
 	moved := g.Position.GetPieceFromSquare(1 << m.From())
-	captured := g.Position.GetPieceFromSquare(1 << m.To())
+	if m.IsDrop() {
+		moved = m.DropPiece(g.Position.ActiveColor)
+	}
+	// Chess960 castling move targets the allied rook.
+	captured := PieceNone
+	if m.Type() != MoveCastling {
+		captured = g.Position.GetPieceFromSquare(1 << m.To())
+	}
 
-	g.Position.MakeMove(m)
+	g.Variant.MakeMove(&g.Position, m)
 
 	// Memorize the captured piece and clear the repetitions
 	// map after applying irreversible moves.
@@ -97,27 +192,32 @@ func (g *Game) PushMove(m Move) {
 	// Store the completed move.
 	g.MoveStack = append(g.MoveStack, CompletedMove{
 		Move:      m,
-		FenString: SerializeFEN(g.Position),
+		FenString: g.Variant.SerializeFEN(g.Position),
 		TimeLeft:  tl,
 	})
 
 	// Generate legal moves for the next turn.
-	GenLegalMoves(g.Position, &g.LegalMoves)
+	g.Variant.GenLegalMoves(g.Position, &g.LegalMoves)
 
 	// Is the en passant capture is not possible, clear the en passant
 	// target, since it can break the threefold-repetition detection
 	// by corrupting the Zobrist hash.
 	// See [IsThreefoldRepetition] commentary
 	ep := 0
-	for i := range g.LegalMoves.LastMoveIndex {
-		if g.LegalMoves.Moves[i].Type() == MoveEnPassant {
+	for m := range g.LegalMoves.All() {
+		if m.Type() == MoveEnPassant {
 			ep = g.Position.EPTarget
 		}
 	}
 	g.Position.EPTarget = ep
 
 	// Add repetition key to detect repetitions.
-	g.Repetitions[zobristKey(g.Position)]++
+	g.Repetitions[ZobristKey(g.Position)]++
+
+	// Start the clock of the next player.
+	if !g.TurnStart.IsZero() {
+		g.TurnStart = time.Now()
+	}
 }
 
 /*
@@ -130,15 +230,14 @@ func (g *Game) PopMove() {
 	}
 
 	// Decrement repetition key.
-	g.Repetitions[zobristKey(g.Position)]--
+	g.Repetitions[ZobristKey(g.Position)]--
 
 	// Pop move from the stack.
 	g.MoveStack = g.MoveStack[:len(g.MoveStack)-1]
 
 	if len(g.MoveStack) == 0 { // No moves left.
 		// Restore position.
-		p := ParseFEN(g.MoveStack[len(g.MoveStack)-1].FenString)
-		g.Position = p
+		g.Position = g.StartPosition
 		// Restore time on the player timers.
 		// Since there are no more completed moves, to restore the initial
 		// clock values just assign a WhiteTime to a BlackTime.
@@ -146,17 +245,38 @@ func (g *Game) PopMove() {
 	} else if len(g.MoveStack)%2 == 0 {
 		// White player has moved.
 		last := g.MoveStack[len(g.MoveStack)-1]
-		g.Position = ParseFEN(last.FenString)
+		g.Position = g.parsePosition(last.FenString)
 		g.WhiteTime = last.TimeLeft
 	} else {
 		// Black player has moved.
 		last := g.MoveStack[len(g.MoveStack)-1]
-		g.Position = ParseFEN(last.FenString)
+		g.Position = g.parsePosition(last.FenString)
 		g.BlackTime = last.TimeLeft
 	}
 
 	// Restore legal moves.
-	GenLegalMoves(g.Position, &g.LegalMoves)
+	g.Variant.GenLegalMoves(g.Position, &g.LegalMoves)
+}
+
+/*
+parsePosition parses the FEN string of the completed move preserving the
+variant and the Chess960 castling rules of the game.
+*/
+func (g *Game) parsePosition(fen string) Position {
+	p := g.Variant.ParseFEN(fen)
+	if g.Position.Chess960 {
+		p.enableChess960()
+	}
+	return p
+}
+
+/*
+Outcome returns the result of the game according to the rules of its variant
+and the winner, or ColorBoth for a draw.  Returns ResultUnscored if the game
+isn't finished yet.
+*/
+func (g *Game) Outcome() (Result, Color) {
+	return g.Variant.Outcome(g)
 }
 
 /*
@@ -180,14 +300,39 @@ func (g *Game) IsThreefoldRepetition() bool {
 	return false
 }
 
+/*
+IsFiftyMove returns true if no capture has been made and no pawn has been moved
+in the last fifty moves of each player.
+*/
+func (g *Game) IsFiftyMove() bool {
+	return g.Position.HalfmoveCnt >= 100
+}
+
 /*
 IsInsufficientMaterial returns true if one of the following statements is true:
   - Both sides have a bare king.
   - One side has a king and a minor piece against a bare king.
   - Both sides have a king and a bishop, the bishops standing on the same color.
   - Both sides have a king and a knight.
+
+Always returns false in King of the Hill, Antichess, Horde and Racing Kings.
+In Three-check, only bare kings are insufficient, since any other piece can
+still give the checks.
 */
 func (g *Game) IsInsufficientMaterial() bool {
+	// Pieces in the Crazyhouse pockets can always be dropped.
+	if g.Position.Pockets != [10]uint8{} {
+		return false
+	}
+
+	switch g.Position.Variant {
+	// Bare kings can still reach the center or the eighth rank.  Any piece
+	// can be lost in Antichess and Horde.
+	case VariantKingOfTheHill, VariantAntichess, VariantHorde,
+		VariantRacingKings:
+		return false
+	}
+
 	// Bitmask for all dark squares.
 	dark := uint64(0xAA55AA55AA55AA55)
 	mat := g.calculateMaterial()
@@ -196,6 +341,10 @@ func (g *Game) IsInsufficientMaterial() bool {
 		return true
 	}
 
+	if g.Position.Variant == VariantThreeCheck {
+		return false
+	}
+
 	if mat == 3 && g.Position.Bitboards[PieceWPawn] == 0 &&
 		g.Position.Bitboards[PieceBPawn] == 0 {
 		return true
@@ -224,17 +373,87 @@ NOTE: If there are no legal moves, but the king is not in check,
 the position is a stalemate.
 */
 func (g *Game) IsCheckmate() bool {
-	isKingInCheck := GenChecksCounter(g.Position.Bitboards,
-		1^g.Position.ActiveColor) > 0
-	return isKingInCheck && g.LegalMoves.LastMoveIndex == 0
+	isKingInCheck := genActiveCheckers(g.Position) != 0
+	return isKingInCheck && g.LegalMoves.Len() == 0
+}
+
+/*
+IsKingExploded returns true if one of the kings has exploded in Atomic.  The
+player who captured next to the enemy king wins.
+*/
+func (g *Game) IsKingExploded() bool {
+	return g.Position.Variant == VariantAtomic &&
+		(g.Position.Bitboards[PieceWKing] == 0 ||
+			g.Position.Bitboards[PieceBKing] == 0)
+}
+
+/*
+IsThreeCheck returns true if one of the players has checked the enemy king three
+times in Three-check.  The player who delivered the third check wins.
+*/
+func (g *Game) IsThreeCheck() bool {
+	return g.Position.Variant == VariantThreeCheck &&
+		(g.Position.Checks[ColorWhite] >= 3 || g.Position.Checks[ColorBlack] >= 3)
+}
+
+/*
+IsKingOfTheHill returns true if one of the kings has reached the d4, e4, d5 or
+e5 square in King of the Hill.  The player who moved the king there wins.
+*/
+func (g *Game) IsKingOfTheHill() bool {
+	kings := g.Position.Bitboards[PieceWKing] | g.Position.Bitboards[PieceBKing]
+	return g.Position.Variant == VariantKingOfTheHill &&
+		kings&(D4|E4|D5|E5) != 0
+}
+
+/*
+IsAntichessWin returns true if the active player has lost all pieces or has no
+legal moves in Antichess.  The active player wins in both cases.
+*/
+func (g *Game) IsAntichessWin() bool {
+	return g.Position.Variant == VariantAntichess &&
+		g.LegalMoves.Len() == 0
+}
+
+/*
+IsHordeCaptured returns true if all white pieces have been captured in Horde.
+Black wins in this case.
+*/
+func (g *Game) IsHordeCaptured() bool {
+	return g.Position.Variant == VariantHorde && g.Position.Bitboards[12] == 0
+}
+
+/*
+IsRaceFinished returns true if one of the kings has reached the eighth rank in
+Racing Kings.  If the white king reaches it first, black is given the last move
+to reach it as well, in which case the game is drawn.
+*/
+func (g *Game) IsRaceFinished() bool {
+	if g.Position.Variant != VariantRacingKings {
+		return false
+	}
+
+	white := g.Position.Bitboards[PieceWKing]&RANK_8 != 0
+	black := g.Position.Bitboards[PieceBKing]&RANK_8 != 0
+	if black || !white {
+		return black
+	}
+
+	// Black is still able to equalize.
+	if g.Position.ActiveColor == ColorBlack {
+		king := bitScan(g.Position.Bitboards[PieceBKing])
+		for m := range g.LegalMoves.All() {
+			if m.From() == king && 1<<m.To()&RANK_8 != 0 {
+				return false
+			}
+		}
+	}
+	return true
 }
 
 // IsMoveLegal checks if the specified move is legal.
 func (g *Game) IsMoveLegal(m Move) bool {
-	for _, move := range g.LegalMoves.Moves {
-		if move == 0x0 {
-			return false
-		}
+	for move := range g.LegalMoves.All() {
 		if move.From() == m.From() && move.To() == m.To() &&
 			move.Type() == m.Type() && move.PromoPiece() == m.PromoPiece() {
 			return true
@@ -243,6 +462,20 @@ func (g *Game) IsMoveLegal(m Move) bool {
 	return false
 }
 
+/*
+History returns an iterator over the completed moves and their indices in the
+[Game.MoveStack].
+*/
+func (g *Game) History() iter.Seq2[int, CompletedMove] {
+	return func(yield func(int, CompletedMove) bool) {
+		for i, m := range g.MoveStack {
+			if !yield(i, m) {
+				return
+			}
+		}
+	}
+}
+
 /*
 SetClock initializes each player’s clock with timeControl and starts it.
 After every completed move, timeBonus seconds are added to the player’s clock.
@@ -252,6 +485,26 @@ func (g *Game) SetClock(timeControl, timeBonus int) {
 	g.BlackTime = timeControl
 	g.TimeBonus = timeBonus
 	g.Clock.Reset(time.Second)
+	g.TurnStart = time.Now()
+}
+
+// StopClock stops the [Clock], e.g. after the end of the game.
+func (g *Game) StopClock() {
+	g.Clock.Stop()
+	g.TurnStart = time.Time{}
+}
+
+/*
+OfferDraw registers the draw offer of the specified player.  If the opponent
+has already offered a draw, the game ends by agreement.  The pending offer is
+declined when the opponent makes a move.
+*/
+func (g *Game) OfferDraw(c Color) {
+	g.DrawOffers[c] = true
+	if g.DrawOffers[1^c] {
+		g.DrawOffers = [2]bool{}
+		g.Result, g.Winner = ResultDrawByAgreement, ColorBoth
+	}
 }
 
 /*
@@ -108,7 +108,8 @@ func TestIsThreefoldRepetition(t *testing.T) {
 
 		got := g.IsThreefoldRepetition()
 		if tc.expected != got {
-			t.Fatalf("case %d failed: expected %t, got %t", i, tc.expected, got)
+			t.Fatalf("case %d failed: expected %t, got %t\n%s", i, tc.expected,
+				got, g.Position)
 		}
 	}
 }
@@ -134,7 +135,7 @@ func TestIsInsufficientMaterial(t *testing.T) {
 
 		got := game.IsInsufficientMaterial()
 		if got != tc.expected {
-			t.Fatalf("expected: %t, got: %t", tc.expected, got)
+			t.Fatalf("expected: %t, got: %t\n%s", tc.expected, got, game.Position)
 		}
 	}
 }
@@ -156,11 +157,109 @@ func TestIsCheckmate(t *testing.T) {
 
 		got := game.IsCheckmate()
 		if got != tc.expected {
-			t.Fatalf("expected: %t, got: %t", tc.expected, got)
+			t.Fatalf("expected: %t, got: %t\n%s", tc.expected, got, game.Position)
 		}
 	}
 }
 
+func TestIsMoveLegal(t *testing.T) {
+	game := NewGame()
+
+	if !game.IsMoveLegal(NewMove(SE4, SE2, MoveNormal)) {
+		t.Fatalf("expected e2e4 to be legal")
+	}
+	if game.IsMoveLegal(NewMove(SE5, SE2, MoveNormal)) {
+		t.Fatalf("expected e2e5 to be illegal")
+	}
+	// Zero move is a1a1.
+	if game.IsMoveLegal(0) {
+		t.Fatalf("expected zero move to be illegal")
+	}
+}
+
+func TestHistory(t *testing.T) {
+	game := NewGame()
+	moves := []Move{
+		NewMove(SE4, SE2, MoveNormal),
+		NewMove(SE5, SE7, MoveNormal),
+		NewMove(SF3, SG1, MoveNormal),
+	}
+
+	for _, m := range moves {
+		game.PushMove(m)
+	}
+
+	cnt := 0
+	for i, completed := range game.History() {
+		if i != cnt || completed.Move != moves[i] {
+			t.Fatalf("expected move %d to be %s got %d %s", cnt,
+				Move2UCI(moves[cnt]), i, Move2UCI(completed.Move))
+		}
+		cnt++
+	}
+
+	if cnt != len(moves) {
+		t.Fatalf("expected %d moves got %d", len(moves), cnt)
+	}
+}
+
+func TestIsThreeCheck(t *testing.T) {
+	g := NewGame(ThreeCheck{})
+	moves := []string{
+		"e2e4", "e7e5", "f1c4", "b8c6", "c4f7", "e8f7", "d1h5", "g7g6",
+		"h5f3",
+	}
+
+	for i, uci := range moves {
+		if g.IsThreeCheck() {
+			t.Fatalf("game ended after %d moves\n%s", i, g.Position)
+		}
+		m, err := UCI2Move(g.Position, uci)
+		if err != nil {
+			t.Fatal(err)
+		}
+		g.PushMove(m)
+	}
+
+	if !g.IsThreeCheck() || g.Position.Checks != [2]uint8{3, 0} {
+		t.Fatalf("expected three checks\n%s", g.Position)
+	}
+
+	g.PopMove()
+	expected := "r1bq1bnr/pppp1k1p/2n3p1/4p2Q/4P3/8/PPPP1PPP/RNB1K1NR w KQ - 0 5 +2+0"
+	if got := SerializeFEN(g.Position); got != expected {
+		t.Fatalf("expected %s got %s", expected, got)
+	}
+}
+
+func TestIsKingOfTheHill(t *testing.T) {
+	testcases := []struct {
+		fen      string
+		variant  VariantKind
+		expected bool
+	}{
+		{"8/8/8/3k4/8/8/8/4K3 w - - 0 1", VariantStandard, false},
+		{"8/8/8/3k4/8/8/8/4K3 w - - 0 1", VariantKingOfTheHill, true},
+		{"8/8/8/8/4K3/8/8/4k3 b - - 0 1", VariantKingOfTheHill, true},
+		{"8/8/2k5/8/8/8/8/4K3 w - - 0 1", VariantKingOfTheHill, false},
+	}
+
+	g := NewGame()
+	for _, tc := range testcases {
+		g.Position = ParseVariantFEN(tc.fen, tc.variant)
+
+		if got := g.IsKingOfTheHill(); got != tc.expected {
+			t.Fatalf("expected %t got %t\n%s", tc.expected, got, g.Position)
+		}
+	}
+
+	// Bare kings can still reach the center.
+	if g.IsInsufficientMaterial() {
+		t.Fatalf("expected sufficient material in King of the Hill\n%s",
+			g.Position)
+	}
+}
+
 func BenchmarkPushMove(b *testing.B) {
 	game := NewGame()
 	pos := ParseFEN("rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1")
@@ -197,7 +296,7 @@ func BenchmarkIsThreefoldRepetition(b *testing.B) {
 		game.PushMove(move)
 		GenLegalMoves(game.Position, &game.LegalMoves)
 		if i < len(moveStack)-1 {
-			game.Repetitions[zobristKey(game.Position)]++
+			game.Repetitions[ZobristKey(game.Position)]++
 		}
 	}
 
@@ -221,3 +320,24 @@ func BenchmarkIsCheckmate(b *testing.B) {
 		game.IsCheckmate()
 	}
 }
+
+func TestOfferDraw(t *testing.T) {
+	g := NewGame()
+	g.OfferDraw(ColorWhite)
+	playUCI(t, g, []string{"e2e4"})
+	if g.DrawOffers != [2]bool{true, false} {
+		t.Fatalf("expected pending white offer got %v", g.DrawOffers)
+	}
+
+	// Black declines the offer by making a move.
+	playUCI(t, g, []string{"e7e5"})
+	if g.DrawOffers != [2]bool{} {
+		t.Fatalf("expected declined offer got %v", g.DrawOffers)
+	}
+
+	g.OfferDraw(ColorWhite)
+	g.OfferDraw(ColorBlack)
+	if g.Result != ResultDrawByAgreement || g.Winner != ColorBoth {
+		t.Fatalf("expected draw by agreement got %s", g.Result)
+	}
+}
@@ -0,0 +1,209 @@
+/*
+gametree.go implements the tree of moves with variations, comments and Numeric
+Annotation Glyphs (NAGs), used for game analysis and PGN with variations.
+*/
+
+package chego
+
+import (
+	"fmt"
+	"iter"
+	"slices"
+)
+
+/*
+TreeNode is a single node of the [GameTree].  The root node holds the starting
+position and no move.
+*/
+type TreeNode struct {
+	// Move leading to the node.  Zero for the root node.
+	Move Move
+	// Position after the move.
+	Position Position
+	// Zobrist key of the position.
+	Hash uint64
+	// Number of halfmoves from the root node.
+	Ply     int
+	Comment string
+	// Numeric Annotation Glyphs, e.g. 1 for "!" and 2 for "?".
+	NAGs   []uint8
+	Parent *TreeNode
+	// Continuations of the node.  The first child continues the main line,
+	// the other ones are the variations.
+	Children []*TreeNode
+}
+
+// GameTree is the tree of moves with the cursor pointing to the current node.
+type GameTree struct {
+	// Rules of the game.  [Standard] by default.
+	Variant Variant
+	Root    *TreeNode
+	Current *TreeNode
+}
+
+/*
+NewGameTree creates a new tree rooted at the specified position.  The moves are
+played by the rules of the specified variant, or [Standard] if no variant is
+specified.
+*/
+func NewGameTree(start Position, variant ...Variant) *GameTree {
+	root := &TreeNode{Position: start, Hash: ZobristKey(start)}
+	return &GameTree{Variant: optionalVariant(variant), Root: root,
+		Current: root}
+}
+
+/*
+AddMove plays the move from the current node and moves the cursor to the
+resulting node.  If the move is already present among the children of the
+current node, the existing node is reused.  Otherwise, the move continues the
+main line if the current node has no children and starts a new variation if it
+has.
+
+Returns an error if the move is illegal.
+*/
+func (t *GameTree) AddMove(m Move) (*TreeNode, error) {
+	for _, child := range t.Current.Children {
+		if child.Move == m {
+			t.Current = child
+			return child, nil
+		}
+	}
+
+	l := MoveList{}
+	t.Variant.GenLegalMoves(t.Current.Position, &l)
+	legal := false
+	for move := range l.All() {
+		if move == m {
+			legal = true
+			break
+		}
+	}
+	if !legal {
+		return nil, fmt.Errorf("illegal move %s", Move2UCI(m))
+	}
+
+	p := t.Current.Position
+	t.Variant.MakeMove(&p, m)
+
+	node := &TreeNode{
+		Move:     m,
+		Position: p,
+		Hash:     ZobristKey(p),
+		Ply:      t.Current.Ply + 1,
+		Parent:   t.Current,
+	}
+	t.Current.Children = append(t.Current.Children, node)
+	t.Current = node
+	return node, nil
+}
+
+/*
+Promote makes the variation containing the node the main line: the node and
+all of its ancestors become the first children of their parents.
+*/
+func (t *GameTree) Promote(n *TreeNode) {
+	for ; n.Parent != nil; n = n.Parent {
+		siblings := n.Parent.Children
+		i := slices.Index(siblings, n)
+		// Shift the preceding siblings to keep the order of variations.
+		copy(siblings[1:i+1], siblings[:i])
+		siblings[0] = n
+	}
+}
+
+/*
+Delete removes the node and its subtree from the tree.  If the cursor points
+into the removed subtree, it's moved to the parent of the node.  The root node
+cannot be deleted.
+*/
+func (t *GameTree) Delete(n *TreeNode) {
+	if n.Parent == nil {
+		return
+	}
+
+	for cur := t.Current; cur != nil; cur = cur.Parent {
+		if cur == n {
+			t.Current = n.Parent
+			break
+		}
+	}
+
+	n.Parent.Children = slices.DeleteFunc(n.Parent.Children,
+		func(child *TreeNode) bool { return child == n })
+	n.Parent = nil
+}
+
+/*
+Forward moves the cursor to the main line continuation of the current node.
+Returns false if the current node has no children.
+*/
+func (t *GameTree) Forward() bool {
+	if len(t.Current.Children) == 0 {
+		return false
+	}
+	t.Current = t.Current.Children[0]
+	return true
+}
+
+/*
+Back moves the cursor to the parent of the current node.  Returns false if the
+cursor points to the root node.
+*/
+func (t *GameTree) Back() bool {
+	if t.Current.Parent == nil {
+		return false
+	}
+	t.Current = t.Current.Parent
+	return true
+}
+
+/*
+GoToPly moves the cursor to the node with the specified ply on the current
+line: back to the ancestor of the current node, or forward along the main line
+continuation.  Returns false and leaves the cursor as is if there is no such
+node.
+*/
+func (t *GameTree) GoToPly(ply int) bool {
+	if ply < 0 {
+		return false
+	}
+
+	n := t.Current
+	for n.Ply > ply {
+		n = n.Parent
+	}
+	for n.Ply < ply && len(n.Children) > 0 {
+		n = n.Children[0]
+	}
+
+	if n.Ply != ply {
+		return false
+	}
+	t.Current = n
+	return true
+}
+
+/*
+Line returns an iterator over the nodes from the root to the current node,
+excluding the root.
+*/
+func (t *GameTree) Line() iter.Seq[*TreeNode] {
+	var line []*TreeNode
+	for n := t.Current; n.Parent != nil; n = n.Parent {
+		line = append(line, n)
+	}
+	slices.Reverse(line)
+	return slices.Values(line)
+}
+
+// Mainline returns an iterator over the main line nodes, excluding the root.
+func (t *GameTree) Mainline() iter.Seq[*TreeNode] {
+	return func(yield func(*TreeNode) bool) {
+		for n := t.Root; len(n.Children) > 0; {
+			n = n.Children[0]
+			if !yield(n) {
+				return
+			}
+		}
+	}
+}
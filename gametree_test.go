@@ -0,0 +1,108 @@
+package chego
+
+import (
+	"strings"
+	"testing"
+)
+
+// addMoves plays the moves in UCI notation in the tree.
+func addMoves(t *testing.T, tree *GameTree, moves ...string) {
+	for _, uci := range moves {
+		m, err := UCI2Move(tree.Current.Position, uci)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tree.AddMove(m); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// mainline returns the main line moves of the tree in UCI notation.
+func mainline(tree *GameTree) string {
+	var moves []string
+	for n := range tree.Mainline() {
+		moves = append(moves, Move2UCI(n.Move))
+	}
+	return strings.Join(moves, " ")
+}
+
+func TestGameTree(t *testing.T) {
+	tree := NewGameTree(ParseFEN(InitialPos))
+	addMoves(t, tree, "e2e4", "e7e5", "g1f3")
+
+	// Sicilian defence as a variation.
+	tree.GoToPly(1)
+	addMoves(t, tree, "c7c5", "g1f3")
+	sicilian := tree.Current
+	sicilian.Comment = "Open Sicilian"
+	sicilian.NAGs = append(sicilian.NAGs, 1)
+
+	if expected := "e2e4 e7e5 g1f3"; mainline(tree) != expected {
+		t.Fatalf("expected %s got %s", expected, mainline(tree))
+	}
+	if sicilian.Ply != 3 || sicilian.Hash != ZobristKey(sicilian.Position) {
+		t.Fatalf("unexpected node %+v", sicilian)
+	}
+
+	// Existing moves are reused.
+	tree.GoToPly(0)
+	addMoves(t, tree, "e2e4")
+	if len(tree.Root.Children) != 1 || len(tree.Current.Children) != 2 {
+		t.Fatalf("expected the existing node to be reused")
+	}
+
+	tree.Promote(sicilian)
+	if expected := "e2e4 c7c5 g1f3"; mainline(tree) != expected {
+		t.Fatalf("expected %s got %s", expected, mainline(tree))
+	}
+
+	tree.Current = sicilian
+	tree.Delete(sicilian.Parent)
+	if expected := "e2e4 e7e5 g1f3"; mainline(tree) != expected {
+		t.Fatalf("expected %s got %s", expected, mainline(tree))
+	}
+	if tree.Current != tree.Root.Children[0] {
+		t.Fatalf("expected the cursor to move to the parent of the deleted node")
+	}
+
+	if _, err := tree.AddMove(NewMove(SE5, SE2, MoveNormal)); err == nil {
+		t.Fatalf("expected illegal move error")
+	}
+}
+
+func TestGameTreeNavigation(t *testing.T) {
+	tree := NewGameTree(ParseFEN(InitialPos))
+	addMoves(t, tree, "d2d4", "d7d5", "c2c4")
+	tree.GoToPly(2)
+	addMoves(t, tree, "g1f3")
+
+	testcases := []struct {
+		name     string
+		action   func() bool
+		ok       bool
+		expected string
+	}{
+		{"back", tree.Back, true, "d2d4 d7d5"},
+		{"forward", tree.Forward, true, "d2d4 d7d5 c2c4"},
+		{"forward at the end", tree.Forward, false, "d2d4 d7d5 c2c4"},
+		{"go to ply 0", func() bool { return tree.GoToPly(0) }, true, ""},
+		{"back at the root", tree.Back, false, ""},
+		{"go to ply 2", func() bool { return tree.GoToPly(2) }, true, "d2d4 d7d5"},
+		{"go to ply 4", func() bool { return tree.GoToPly(4) }, false, "d2d4 d7d5"},
+	}
+
+	for _, tc := range testcases {
+		if ok := tc.action(); ok != tc.ok {
+			t.Fatalf("%s: expected %t got %t", tc.name, tc.ok, ok)
+		}
+
+		var line []string
+		for n := range tree.Line() {
+			line = append(line, Move2UCI(n.Move))
+		}
+		if got := strings.Join(line, " "); got != tc.expected {
+			t.Fatalf("%s: expected %s got %s", tc.name, tc.expected, got)
+		}
+	}
+}
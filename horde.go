@@ -0,0 +1,19 @@
+/*
+horde.go implements the rules of Horde.  White has 36 pawns and no king, and
+the pawns on the first rank can move double forward.  White wins by checkmate,
+black wins by capturing all white pieces.
+
+See https://lichess.org/variant/horde
+*/
+
+package chego
+
+/*
+genHordeMoves appends legal moves of the player without the king to the given
+move list.  Every pseudo-legal move is legal, since there is no king to check.
+*/
+func genHordeMoves(p Position, l *MoveList) {
+	genPawnMoves(p, l)
+
+	genNormalMoves(p, l)
+}
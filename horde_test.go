@@ -0,0 +1,52 @@
+package chego
+
+import "testing"
+
+// Expected values are taken from the lichess perft suite.
+func TestHordePerft(t *testing.T) {
+	p := ParseVariantFEN(InitialHordePos, VariantHorde)
+	expected := []int{8, 128, 1274, 23310}
+
+	for i, nodes := range expected {
+		if got := Perft(p, i+1); got != nodes {
+			t.Fatalf("depth %d: expected %d got %d\n%s", i+1, nodes, got, p)
+		}
+	}
+}
+
+func TestGenHordeMoves(t *testing.T) {
+	testcases := []struct {
+		name     string
+		fen      string
+		expected int
+	}{
+		// Single and double pushes from the first rank.
+		{"first rank", "4k3/8/8/8/8/8/8/P7 w - - 0 1", 2},
+		// The pinned-looking pawn is free to move without the king.
+		{"no king", "4k3/4r3/8/8/8/8/4P3/8 w - - 0 1", 2},
+	}
+
+	for _, tc := range testcases {
+		l := MoveList{}
+		GenLegalMoves(ParseVariantFEN(tc.fen, VariantHorde), &l)
+		if int(l.LastMoveIndex) != tc.expected {
+			t.Fatalf("%s: expected %d moves got %d", tc.name, tc.expected,
+				l.LastMoveIndex)
+		}
+	}
+}
+
+func TestIsHordeCaptured(t *testing.T) {
+	g := NewGame(Horde{})
+	if g.IsHordeCaptured() {
+		t.Fatalf("unexpected game end\n%s", g.Position)
+	}
+
+	g.Position = ParseVariantFEN("4k3/8/8/8/8/8/3p4/4P3 b - - 0 1", VariantHorde)
+	GenLegalMoves(g.Position, &g.LegalMoves)
+	g.PushMove(NewPromotionMove(SE1, SD2, PromotionQueen))
+
+	if !g.IsHordeCaptured() || g.IsCheckmate() {
+		t.Fatalf("expected black to win\n%s", g.Position)
+	}
+}
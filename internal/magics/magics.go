@@ -0,0 +1,130 @@
+/*
+Package main provides a tool to verify the predefined magic numbers and to
+search for new ones.  Like the perft tool, it is only used for debugging and
+the chego users won't be able to import this package.
+*/
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"os"
+	"strings"
+
+	"github.com/BelikovArtem/chego"
+)
+
+// main verifies the predefined magics or searches for the new ones.
+func main() {
+	// Relevant occupancy masks are initialized along with the attack tables.
+	chego.InitAttackTables()
+
+	search := flag.Bool("search", false, "Wether to search for new magic numbers")
+	shrink := flag.Int("shrink", 0, "Number of index bits to subtract from "+
+		"the predefined bit counts to get smaller attack tables")
+	tries := flag.Int("tries", 10_000_000, "Number of tries per square")
+	seed := flag.Uint64("seed", 1, "Seed of the pseudo-random generator")
+
+	flag.Parse()
+
+	bishopBits, rookBits := chego.PredefinedMagicBits()
+
+	if !*search {
+		if err := chego.VerifyMagics(); err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("Predefined magic numbers are valid")
+		bishopMagics, rookMagics := chego.PredefinedMagics()
+		report(bishopMagics, rookMagics, bishopBits, rookBits)
+		return
+	}
+
+	r := rand.New(rand.NewPCG(*seed, *seed))
+
+	var bishopMagics, rookMagics [64]uint64
+	for square := range 64 {
+		bishopMagics[square], bishopBits[square] = find(chego.PieceWBishop,
+			square, bishopBits[square], *shrink, *tries, r)
+		rookMagics[square], rookBits[square] = find(chego.PieceWRook,
+			square, rookBits[square], *shrink, *tries, r)
+	}
+
+	fmt.Print(table("bishopMagicNumbers", "[64]uint64", bishopMagics[:]))
+	fmt.Print(table("rookMagicNumbers", "[64]uint64", rookMagics[:]))
+	fmt.Print(table("bishopBitCount", "[64]int", bishopBits[:]))
+	fmt.Print(table("rookBitCount", "[64]int", rookBits[:]))
+
+	report(bishopMagics, rookMagics, bishopBits, rookBits)
+}
+
+/*
+find searches for the magic number of the slider standing on the specified
+square.  First tries to find the magic for the table which is shrink bits
+smaller and falls back to the predefined bit count.
+*/
+func find(slider chego.Piece, square, bits, shrink, tries int,
+	r *rand.Rand) (uint64, int) {
+
+	for b := bits - shrink; b <= bits; b++ {
+		if magic, ok := chego.FindMagic(slider, square, b, tries, r); ok {
+			return magic, b
+		}
+	}
+
+	name := "Bishop"
+	if slider == chego.PieceWRook {
+		name = "Rook"
+	}
+	log.Printf("%s magic on %s not found, try to increase the number of tries",
+		name, chego.Square2String[square])
+	os.Exit(1)
+	return 0, 0
+}
+
+// table formats the lookup table as a Go composite literal.
+func table[T uint64 | int](name, typ string, values []T) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "%s = %s{\n", name, typ)
+	for _, v := range values {
+		if typ == "[64]uint64" {
+			fmt.Fprintf(&b, "\t%#x,\n", v)
+		} else {
+			fmt.Fprintf(&b, "\t%d,\n", v)
+		}
+	}
+	b.WriteString("}\n")
+
+	return b.String()
+}
+
+/*
+report builds the packed attack tables indexed with the magics and prints the
+memory they use along with the memory used by the plain tables of the move
+generator, which don't depend on the magics.
+*/
+func report(bishopMagics, rookMagics [64]uint64, bishopBits, rookBits [64]int) {
+	bishops, err := chego.NewMagicTable(chego.PieceWBishop, bishopMagics,
+		bishopBits)
+	if err != nil {
+		log.Fatalf("Bishop %v", err)
+	}
+	rooks, err := chego.NewMagicTable(chego.PieceWRook, rookMagics, rookBits)
+	if err != nil {
+		log.Fatalf("Rook %v", err)
+	}
+
+	maxBishop, maxRook := 0, 0
+	predefinedBishop, predefinedRook := chego.PredefinedMagicBits()
+	for square := range 64 {
+		maxBishop = max(maxBishop, predefinedBishop[square])
+		maxRook = max(maxRook, predefinedRook[square])
+	}
+	// Each entry is a 64-bit bitboard.
+	plain := 64 * (1<<maxBishop + 1<<maxRook) * 8
+
+	log.Printf("Plain attack tables: %d bytes", plain)
+	log.Printf("Packed attack tables: %d bytes", bishops.Size()+rooks.Size())
+}
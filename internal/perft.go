@@ -6,111 +6,305 @@ be able to import this package.
 package main
 
 import (
+	"bufio"
 	"flag"
+	"fmt"
 	"log"
 	"os"
+	"runtime"
 	"runtime/pprof"
+	"slices"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/BelikovArtem/chego"
 )
 
-// result information will be printed is the perft is executed with the
-// verbose flag.
-type result struct {
-	nodes        int
-	captures     int
-	epCaptures   int
-	castles      int
-	promotions   int
-	checks       int
-	doubleChecks int
-	checkmates   int
+/*
+hashEntry stores the node count of a single subtree.  The entry is written and
+read without locks: the key is stored XORed with the data, so a torn entry
+written concurrently by several goroutines never passes the key check.
+See https://www.cis.uab.edu/hyatt/hashing.html
+*/
+type hashEntry struct {
+	// Zobrist key of the subtree root XORed with data.
+	key atomic.Uint64
+	// 0-7 bits: depth, 8-63 bits: node count.
+	data atomic.Uint64
+}
+
+// hashTable is a perft hash table shared between all goroutines.
+type hashTable struct {
+	entries []hashEntry
+	mask    uint64
 }
 
 /*
-perft is a debugging function that walks through the move generation tree of
-strictly legal moves to a given depth and counts the number of visited leaf
-nodes. The resulting count is then compared to predetermined values.
+newHashTable allocates a hash table of the specified size in megabytes.  The
+number of entries is rounded down to the power of two.
+*/
+func newHashTable(megabytes int) *hashTable {
+	n := uint64(megabytes) << 20 / 16
+
+	size := uint64(1)
+	for size*2 <= n {
+		size *= 2
+	}
 
-See https://www.chessprogramming.org/Perft_Results
+	return &hashTable{
+		entries: make([]hashEntry, size),
+		mask:    size - 1,
+	}
+}
+
+// probe returns the node count of the subtree, if it is stored in the table.
+func (t *hashTable) probe(key uint64, depth int) (int, bool) {
+	e := &t.entries[key&t.mask]
+	data := e.data.Load()
+	if e.key.Load()^data != key || int(data&0xFF) != depth {
+		return 0, false
+	}
+	return int(data >> 8), true
+}
+
+// store writes the node count of the subtree into the table.
+func (t *hashTable) store(key uint64, depth, nodes int) {
+	e := &t.entries[key&t.mask]
+	data := uint64(nodes)<<8 | uint64(depth)
+	e.key.Store(key ^ data)
+	e.data.Store(data)
+}
+
+/*
+perftHashed follows the same principle as the perft function, except it caches
+the node counts of the visited subtrees in the hash table t.  Transpositions
+are counted only once.
 */
-func perft(p chego.Position, depth int) int {
+func perftHashed(p chego.Position, depth int, t *hashTable) int {
 	l := chego.MoveList{}
 	nodes := 0
 
 	chego.GenLegalMoves(p, &l)
 
 	if depth == 1 {
-		return int(l.LastMoveIndex)
+		return l.Len()
+	}
+
+	key := chego.ZobristKey(p)
+	if cnt, ok := t.probe(key, depth); ok {
+		return cnt
 	}
 
 	var prev chego.Position
-	for i := range l.LastMoveIndex {
+	for m := range l.All() {
 		prev = p
-		p.MakeMove(l.Moves[i])
+		p.MakeMove(m)
 
-		nodes += perft(p, depth-1)
+		nodes += perftHashed(p, depth-1, t)
 
 		p = prev
 	}
 
+	t.store(key, depth, nodes)
 	return nodes
 }
 
 /*
-perftVerbose follows the same principle as the perft function, except it writes
-detailed move debugging information to r. Use this function to debug and find
-invalid branches in the move generation tree, not to measure performance.
+splitPerft splits the legal moves of the root position across the specified
+number of goroutines and returns the moves along with the node counts of their
+subtrees.  If t is not nil, the subtree node counts are cached in it.
 */
-func perftVerbose(p chego.Position, depth int, r *result, isRoot bool) int {
+func splitPerft(p chego.Position, depth, workers int,
+	t *hashTable) ([]chego.Move, []int) {
+
 	l := chego.MoveList{}
+	chego.GenLegalMoves(p, &l)
+	moves := slices.Collect(l.All())
+
+	counts := make([]int, len(moves))
+	indices := make(chan int)
+	var wg sync.WaitGroup
+
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for i := range indices {
+				if depth == 1 {
+					counts[i] = 1
+					continue
+				}
+
+				child := p
+				child.MakeMove(moves[i])
+
+				if t != nil {
+					counts[i] = perftHashed(child, depth-1, t)
+				} else {
+					counts[i] = chego.Perft(child, depth-1)
+				}
+			}
+		}()
+	}
+
+	for i := range moves {
+		indices <- i
+	}
+	close(indices)
+
+	wg.Wait()
+
+	return moves, counts
+}
+
+/*
+divide is a debugging function that runs the perft for each legal move of the
+root position separately and prints the node count of every subtree in the
+format used by other engines ("e2e4: 20"), followed by the total node count.
+Compare its output with the output of another program to find the invalid
+branch in the move generation tree.
+*/
+func divide(p chego.Position, depth, workers int, t *hashTable) int {
+	moves, counts := splitPerft(p, depth, workers, t)
 	nodes := 0
 
-	chego.GenLegalMoves(p, &l)
+	for i, cnt := range counts {
+		fmt.Printf("%s: %d\n", chego.Move2UCI(moves[i]), cnt)
+		nodes += cnt
+	}
 
-	if depth == 1 {
-		return int(l.LastMoveIndex)
+	fmt.Printf("\nNodes searched: %d\n", nodes)
+	return nodes
+}
+
+// variants maps the variant names accepted by the -variant flag and the EPD
+// test suite to the chego variants.
+var variants = map[string]chego.VariantKind{
+	"standard":      chego.VariantStandard,
+	"crazyhouse":    chego.VariantCrazyhouse,
+	"threecheck":    chego.VariantThreeCheck,
+	"kingofthehill": chego.VariantKingOfTheHill,
+	"atomic":        chego.VariantAtomic,
+	"antichess":     chego.VariantAntichess,
+	"horde":         chego.VariantHorde,
+	"racingkings":   chego.VariantRacingKings,
+}
+
+// suiteEntry is a single line of the EPD test suite.
+type suiteEntry struct {
+	fen     string
+	variant chego.VariantKind
+	// Expected node counts indexed by depth.  Zero means that the node count
+	// for the depth is not specified.
+	expected []int
+}
+
+/*
+parseSuite parses the EPD test suite.  Each line of the suite consists of a
+position and a list of expected node counts separated by semicolons:
+
+	r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1 ;D1 26 ;D2 568
+
+Positions of the variants which cannot be detected from the FEN string are
+marked with the variant name:
+
+	rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1 ;variant atomic ;D1 20
+
+The halfmove and fullmove counters may be omitted.  Empty lines and lines
+starting with '#' are ignored.
+*/
+func parseSuite(path string) ([]suiteEntry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
 	}
+	defer f.Close()
 
-	c := p.ActiveColor
-	var prev chego.Position
-	for i := range l.LastMoveIndex {
-		if p.GetPieceFromSquare(1<<l.Moves[i].To()) != chego.PieceNone {
-			r.captures++
+	var suite []suiteEntry
+
+	scanner := bufio.NewScanner(f)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' {
+			continue
 		}
 
-		prev = p
-		p.MakeMove(l.Moves[i])
+		fields := strings.Split(line, ";")
 
-		cnt := chego.GenChecksCounter(p.Bitboards, 1^c)
-		if cnt > 0 {
-			r.checks++
-		}
-		if cnt > 1 {
-			r.doubleChecks++
+		e := suiteEntry{fen: strings.TrimSpace(fields[0])}
+		// EPD positions usually omit the move counters.
+		if len(strings.Fields(e.fen)) == 4 {
+			e.fen += " 0 1"
 		}
 
-		cnt = perftVerbose(p, depth-1, r, false)
-		if isRoot {
-			log.Printf("%s %d", chego.Move2UCI(l.Moves[i]), cnt)
+		for _, field := range fields[1:] {
+			if name, ok := strings.CutPrefix(strings.TrimSpace(field), "variant "); ok {
+				v, ok := variants[name]
+				if !ok {
+					return nil, fmt.Errorf("line %d: unknown variant %q", lineNum,
+						name)
+				}
+				e.variant = v
+				continue
+			}
+
+			var depth, nodes int
+			_, err := fmt.Sscanf(strings.TrimSpace(field), "D%d %d", &depth, &nodes)
+			if err != nil || depth < 1 {
+				return nil, fmt.Errorf("line %d: invalid node count %q", lineNum,
+					field)
+			}
+
+			for len(e.expected) <= depth {
+				e.expected = append(e.expected, 0)
+			}
+			e.expected[depth] = nodes
 		}
-		nodes += cnt
 
-		switch l.Moves[i].Type() {
-		case chego.MoveCastling:
-			r.castles++
-		case chego.MoveEnPassant:
-			r.epCaptures++
-		case chego.MovePromotion:
-			r.promotions++
+		suite = append(suite, e)
+	}
+
+	return suite, scanner.Err()
+}
+
+/*
+runSuite runs the perft for each position of the EPD test suite up to the
+specified depth and reports the node counts which differ from the expected
+ones.  Returns the number of mismatches.
+*/
+func runSuite(suite []suiteEntry, maxDepth, workers int,
+	t *hashTable) (mismatches int) {
+	for i, e := range suite {
+		p := chego.ParseVariantFEN(e.fen, e.variant)
+		if e.variant == chego.VariantStandard {
+			p = chego.ParseFEN(e.fen)
 		}
 
-		p = prev
+		for depth := 1; depth < len(e.expected) && depth <= maxDepth; depth++ {
+			if e.expected[depth] == 0 {
+				continue
+			}
+
+			got := 0
+			_, counts := splitPerft(p, depth, workers, t)
+			for _, cnt := range counts {
+				got += cnt
+			}
+
+			if got != e.expected[depth] {
+				log.Printf("MISMATCH #%d %s depth %d: expected %d got %d",
+					i+1, e.fen, depth, e.expected[depth], got)
+				mismatches++
+			} else {
+				log.Printf("OK #%d %s depth %d: %d", i+1, e.fen, depth, got)
+			}
+		}
 	}
 
-	return nodes
+	return mismatches
 }
 
 // main runs the perft and measures it's execution time.
@@ -120,41 +314,29 @@ func main() {
 	chego.InitAttackTables()
 
 	depth := flag.Int("depth", 2, "Performance test depth")
+	fen := flag.String("fen", chego.InitialPos, "Root position")
+	variant := flag.String("variant", "", "Variant of the root position, "+
+		"if it cannot be detected from the FEN string")
 	verbose := flag.Bool("verbose", false, "Wether to print the debug info")
+	div := flag.Bool("divide", false, "Wether to print node counts per root move")
+	epd := flag.String("epd", "", "EPD test suite to run up to the specified depth")
+	workers := flag.Int("workers", 1, "Number of goroutines to split root moves across")
+	hash := flag.Int("hash", 0, "Size of the shared hash table in megabytes")
 	cpuprofile := flag.String("cpuprofile", "", "File to write a cpu profile")
 	memprofile := flag.String("memprofile", "", "File to write a memory profile")
 
 	flag.Parse()
 
-	r := &result{}
-
-	p := chego.ParseFEN(chego.InitialPos)
-
-	start := time.Now()
-	defer func() {
-		elapsed := time.Since(start)
+	if *workers < 1 {
+		*workers = runtime.NumCPU()
+	}
 
-		if *verbose {
-			log.Printf("\nRoot position:\n%s\n\n\t%s\n\n",
-				position(chego.ParseFEN(chego.InitialPos)), chego.InitialPos)
-			log.Printf("\t%d\t%d\t\t%d\t%d\t%d\t%d\t%d\t%d\t%d\t",
-				*depth,
-				r.nodes,
-				r.captures,
-				r.epCaptures,
-				r.castles,
-				r.promotions,
-				r.checks,
-				r.doubleChecks,
-				r.checkmates,
-			)
-			log.Printf("Elapsed time: %d ns", elapsed.Nanoseconds())
-		} else {
-
-			log.Printf("Nodes reached: %d", r.nodes)
-			log.Printf("Elapsed time: %d ns", elapsed.Nanoseconds())
-		}
-	}()
+	var t *hashTable
+	if *hash > 0 {
+		// Zobrist keys are used to index the hash table.
+		chego.InitZobristKeys()
+		t = newHashTable(*hash)
+	}
 
 	if *cpuprofile != "" {
 		f, err := os.Create(*cpuprofile)
@@ -173,66 +355,75 @@ func main() {
 		defer f.Close()
 	}
 
-	if *verbose {
-		r.nodes = perftVerbose(p, *depth, r, true)
-	} else {
-		r.nodes = perft(p, *depth)
-	}
-}
-
-// position formats a full chess position into a string.
-func position(p chego.Position) string {
-	var posStr strings.Builder
-
-	for rank := 7; rank >= 0; rank-- {
-		posStr.WriteByte(byte(rank) + 1 + '0')
-		posStr.WriteString("  ")
-
-		for file := 0; file < 8; file++ {
-			square := uint64(1 << (8*rank + file))
+	if *epd != "" {
+		suite, err := parseSuite(*epd)
+		if err != nil {
+			log.Fatal(err)
+		}
 
-			symbol := byte('.')
+		start := time.Now()
+		mismatches := runSuite(suite, *depth, *workers, t)
+		log.Printf("Elapsed time: %d ns", time.Since(start).Nanoseconds())
 
-			for i := chego.PieceWPawn; i <= chego.PieceBKing; i++ {
-				if square&p.Bitboards[i] != 0 {
-					symbol = chego.PieceSymbols[i]
-					break
-				}
-			}
+		if mismatches > 0 {
+			log.Printf("%d mismatches found", mismatches)
+			pprof.StopCPUProfile()
+			os.Exit(1)
+		}
+		return
+	}
 
-			posStr.WriteByte(symbol)
-			posStr.WriteString("  ")
+	p := chego.ParseFEN(*fen)
+	if *variant != "" {
+		v, ok := variants[*variant]
+		if !ok {
+			log.Fatalf("unknown variant %q", *variant)
 		}
-		posStr.WriteByte('\n')
+		p = chego.ParseVariantFEN(*fen, v)
 	}
 
-	posStr.WriteString("   a  b  c  d  e  f  g  h\nActive color: ")
+	if *verbose {
+		start := time.Now()
+		s := chego.PerftVerbose(p, *depth)
+		elapsed := time.Since(start)
 
-	if p.ActiveColor == chego.ColorWhite {
-		posStr.WriteString("white\nEn passant: ")
-	} else {
-		posStr.WriteString("black\nEn passant: ")
+		log.Printf("\nRoot position:\n%s\n\n\t%s\n\n", p, *fen)
+		log.Printf("\tDepth\tNodes\t\tCaptures\tE.p.\tCastles\tPromotions" +
+			"\tChecks\tDiscovery Checks\tDouble Checks\tCheckmates")
+		log.Printf("\t%d\t%d\t\t%d\t\t%d\t%d\t%d\t\t%d\t%d\t\t\t%d\t\t%d",
+			*depth,
+			s.Nodes,
+			s.Captures,
+			s.EnPassant,
+			s.Castles,
+			s.Promotions,
+			s.Checks,
+			s.DiscoveredChecks,
+			s.DoubleChecks,
+			s.Checkmates,
+		)
+		log.Printf("Elapsed time: %d ns", elapsed.Nanoseconds())
+		return
 	}
 
-	if p.EPTarget == 0 {
-		posStr.WriteString("none\nCastling rights: ")
-	} else {
-		posStr.WriteString(chego.Square2String[p.EPTarget])
-		posStr.WriteString("\nCastling rights: ")
-	}
+	nodes := 0
 
-	if p.CastlingRights&chego.CastlingWhiteShort != 0 {
-		posStr.WriteByte('K')
-	}
-	if p.CastlingRights&chego.CastlingWhiteLong != 0 {
-		posStr.WriteByte('Q')
-	}
-	if p.CastlingRights&chego.CastlingBlackShort != 0 {
-		posStr.WriteByte('k')
-	}
-	if p.CastlingRights&chego.CastlingBlackLong != 0 {
-		posStr.WriteByte('q')
+	start := time.Now()
+	switch {
+	case *div:
+		nodes = divide(p, *depth, *workers, t)
+	case *workers > 1 || t != nil:
+		_, counts := splitPerft(p, *depth, *workers, t)
+		for _, cnt := range counts {
+			nodes += cnt
+		}
+	default:
+		nodes = chego.Perft(p, *depth)
 	}
+	elapsed := time.Since(start)
 
-	return posStr.String()
+	log.Printf("Nodes reached: %d", nodes)
+	log.Printf("Elapsed time: %d ns", elapsed.Nanoseconds())
+	log.Printf("Nodes per second: %d",
+		int64(nodes)*int64(time.Second)/max(elapsed.Nanoseconds(), 1))
 }
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"os"
+	"testing"
+
+	"github.com/BelikovArtem/chego"
+)
+
+func TestMain(m *testing.M) {
+	chego.InitAttackTables()
+	chego.InitZobristKeys()
+	os.Exit(m.Run())
+}
+
+/*
+TestSuiteHashed runs the whole suite with a single hash table shared across the
+positions of all variants, so the subtrees of the different variants must not
+collide.
+*/
+func TestSuiteHashed(t *testing.T) {
+	suite, err := parseSuite("perftsuite.epd")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n := runSuite(suite, 4, 4, newHashTable(64)); n != 0 {
+		t.Fatalf("expected no mismatches got %d", n)
+	}
+}
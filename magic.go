@@ -0,0 +1,212 @@
+/*
+magic.go implements the search and verification of the magic numbers used to
+index the slider attack tables.
+
+See https://www.chessprogramming.org/Looking_for_Magics
+*/
+
+package chego
+
+import (
+	"fmt"
+	"math/rand/v2"
+)
+
+/*
+FindMagic searches for a magic number which maps every relevant occupancy of
+the slider (bishop or rook of any color) standing on the specified square into
+the attack table of 1<<bits entries without destructive collisions.  Returns
+false if the magic number wasn't found after the specified number of tries.
+
+Use the bit counts smaller than the predefined ones to search for magics which
+require smaller attack tables.
+
+NOTE: [InitAttackTables] must be called before using this function.
+*/
+func FindMagic(slider Piece, square, bits, tries int,
+	r *rand.Rand) (uint64, bool) {
+
+	mask, occupancies, attacks := sliderOccupancies(slider, square)
+	used := make([]uint64, 1<<bits)
+
+	for range tries {
+		// Magics with a low number of set bits work better.
+		magic := r.Uint64() & r.Uint64() & r.Uint64()
+
+		// Skip the magics which map the mask into too few high bits.
+		if CountBits(mask*magic&0xFF00000000000000) < 6 {
+			continue
+		}
+
+		if isMagic(occupancies, attacks, magic, bits, used) {
+			return magic, true
+		}
+	}
+
+	return 0, false
+}
+
+/*
+VerifyMagic checks whether the magic number maps every relevant occupancy of
+the slider (bishop or rook of any color) standing on the specified square into
+the attack table of 1<<bits entries without destructive collisions.  The
+collisions of the occupancies which produce the same attacks are constructive,
+since they share the table entry.
+
+NOTE: [InitAttackTables] must be called before using this function.
+*/
+func VerifyMagic(slider Piece, square int, magic uint64, bits int) bool {
+	_, occupancies, attacks := sliderOccupancies(slider, square)
+	return isMagic(occupancies, attacks, magic, bits, make([]uint64, 1<<bits))
+}
+
+/*
+VerifyMagics verifies the predefined magic numbers and relevant occupancy bit
+counts of each square.  Returns an error describing the first invalid magic.
+
+NOTE: [InitAttackTables] must be called before using this function.
+*/
+func VerifyMagics() error {
+	for square := range 64 {
+		if !VerifyMagic(PieceWBishop, square, bishopMagicNumbers[square],
+			bishopBitCount[square]) {
+			return fmt.Errorf("bishop magic on %s has destructive collisions",
+				Square2String[square])
+		}
+
+		if !VerifyMagic(PieceWRook, square, rookMagicNumbers[square],
+			rookBitCount[square]) {
+			return fmt.Errorf("rook magic on %s has destructive collisions",
+				Square2String[square])
+		}
+	}
+	return nil
+}
+
+/*
+MagicTable stores the slider attacks indexed with the magic numbers.  Unlike
+bishopAttacks and rookAttacks, where each square has a table large enough for
+the largest bit count, the tables of all squares are packed into a single
+shared array, so each square takes only 1<<bits entries.  This allows to use
+the magics found with the smaller bit counts to reduce the memory footprint.
+
+See https://www.chessprogramming.org/Magic_Bitboards#Fancy
+*/
+type MagicTable struct {
+	attacks []uint64
+	// Offset of each square's table in the attacks.
+	offsets [64]int
+	masks   [64]uint64
+	magics  [64]uint64
+	bits    [64]int
+}
+
+/*
+NewMagicTable builds the packed attack table of the slider (bishop or rook of
+any color) from the magic numbers and bit counts of each square.  Returns an
+error if one of the magics has destructive collisions.
+
+NOTE: [InitAttackTables] must be called before using this function.
+*/
+func NewMagicTable(slider Piece, magics [64]uint64,
+	bits [64]int) (*MagicTable, error) {
+
+	t := &MagicTable{magics: magics, bits: bits}
+
+	size := 0
+	for square := range 64 {
+		t.offsets[square] = size
+		size += 1 << bits[square]
+	}
+	t.attacks = make([]uint64, size)
+
+	for square := range 64 {
+		mask, occupancies, attacks := sliderOccupancies(slider, square)
+		t.masks[square] = mask
+
+		table := t.attacks[t.offsets[square] : t.offsets[square]+1<<bits[square]]
+		if !isMagic(occupancies, attacks, magics[square], bits[square], table) {
+			return nil, fmt.Errorf("magic on %s has destructive collisions",
+				Square2String[square])
+		}
+	}
+
+	return t, nil
+}
+
+// Attacks returns the attacks of the slider standing on the square.
+func (t *MagicTable) Attacks(square int, occupancy uint64) uint64 {
+	key := (occupancy & t.masks[square]) * t.magics[square] >>
+		(64 - t.bits[square])
+	return t.attacks[t.offsets[square]+int(key)]
+}
+
+// Size returns the size of the table in bytes.
+func (t *MagicTable) Size() int { return len(t.attacks) * 8 }
+
+// PredefinedMagics returns the predefined magic numbers.
+func PredefinedMagics() (bishopMagics, rookMagics [64]uint64) {
+	return bishopMagicNumbers, rookMagicNumbers
+}
+
+// PredefinedMagicBits returns the predefined relevant occupancy bit counts.
+func PredefinedMagicBits() (bishopBits, rookBits [64]int) {
+	return bishopBitCount, rookBitCount
+}
+
+/*
+sliderOccupancies returns the relevant occupancy mask of the slider standing
+on the specified square, along with every subset of the mask and the attacks
+corresponding to it.
+*/
+func sliderOccupancies(slider Piece, square int) (mask uint64,
+	occupancies, attacks []uint64) {
+
+	isRook := slider == PieceWRook || slider == PieceBRook
+
+	mask = bishopOccupancy[square]
+	if isRook {
+		mask = rookOccupancy[square]
+	}
+
+	bitCount := CountBits(mask)
+	occupancies = make([]uint64, 1<<bitCount)
+	attacks = make([]uint64, 1<<bitCount)
+
+	for i := range occupancies {
+		occupancies[i] = genOccupancy(i, bitCount, mask)
+
+		if isRook {
+			attacks[i] = genRookAttacks(1<<square, occupancies[i])
+		} else {
+			attacks[i] = genBishopAttacks(1<<square, occupancies[i])
+		}
+	}
+
+	return mask, occupancies, attacks
+}
+
+/*
+isMagic checks whether the magic number maps the occupancies into the table of
+1<<bits entries without destructive collisions.  used is the table of 1<<bits
+entries, which is filled with the attacks.
+*/
+func isMagic(occupancies, attacks []uint64, magic uint64, bits int,
+	used []uint64) bool {
+
+	clear(used)
+
+	for i, occupancy := range occupancies {
+		key := occupancy * magic >> (64 - bits)
+
+		// Slider attacks are never empty, so the zero value marks an
+		// unused entry.
+		if used[key] == 0 {
+			used[key] = attacks[i]
+		} else if used[key] != attacks[i] {
+			return false
+		}
+	}
+
+	return true
+}
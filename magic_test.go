@@ -0,0 +1,114 @@
+package chego
+
+import (
+	"math/rand/v2"
+	"testing"
+)
+
+func TestVerifyMagics(t *testing.T) {
+	if err := VerifyMagics(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestVerifyMagic(t *testing.T) {
+	// Zero maps every occupancy into the same entry.
+	if VerifyMagic(PieceWRook, SE4, 0, rookBitCount[SE4]) {
+		t.Fatalf("expected zero magic to be invalid")
+	}
+
+	// The predefined magic does not fit into the smaller table.
+	if VerifyMagic(PieceWRook, SA1, rookMagicNumbers[SA1], 8) {
+		t.Fatalf("expected magic to be invalid for the smaller table")
+	}
+}
+
+func TestFindMagic(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+
+	for _, square := range []int{SA1, SE4, SH8} {
+		magic, ok := FindMagic(PieceWBishop, square, bishopBitCount[square],
+			1_000_000, r)
+		if !ok || !VerifyMagic(PieceWBishop, square, magic, bishopBitCount[square]) {
+			t.Fatalf("bishop magic on %s not found", Square2String[square])
+		}
+
+		magic, ok = FindMagic(PieceWRook, square, rookBitCount[square],
+			1_000_000, r)
+		if !ok || !VerifyMagic(PieceWRook, square, magic, rookBitCount[square]) {
+			t.Fatalf("rook magic on %s not found", Square2String[square])
+		}
+	}
+}
+
+func TestMagicTable(t *testing.T) {
+	bishopMagics, rookMagics := PredefinedMagics()
+	bishopBits, rookBits := PredefinedMagicBits()
+
+	bishops, err := NewMagicTable(PieceWBishop, bishopMagics, bishopBits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rooks, err := NewMagicTable(PieceWRook, rookMagics, rookBits)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if size := bishops.Size() + rooks.Size(); size != (5248+102400)*8 {
+		t.Fatalf("expected size %d got %d", (5248+102400)*8, size)
+	}
+
+	r := rand.New(rand.NewPCG(1, 2))
+	for range 10_000 {
+		square, occupancy := r.IntN(64), r.Uint64()&r.Uint64()
+
+		expected := lookupBishopAttacks(square, occupancy)
+		if got := bishops.Attacks(square, occupancy); got != expected {
+			t.Fatalf("bishop on %s: expected %016x got %016x",
+				Square2String[square], expected, got)
+		}
+
+		expected = lookupRookAttacks(square, occupancy)
+		if got := rooks.Attacks(square, occupancy); got != expected {
+			t.Fatalf("rook on %s: expected %016x got %016x",
+				Square2String[square], expected, got)
+		}
+	}
+}
+
+func TestMagicTableBits(t *testing.T) {
+	bishopMagics, _ := PredefinedMagics()
+	bishopBits, _ := PredefinedMagicBits()
+
+	// The magic which fits into the table also fits into the larger one, so
+	// the square tables of different sizes are packed.
+	bishopBits[SA1]++
+	bishops, err := NewMagicTable(PieceWBishop, bishopMagics, bishopBits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bishops.Size() != (5248+64)*8 {
+		t.Fatalf("expected size %d got %d", (5248+64)*8, bishops.Size())
+	}
+	for key := range 1 << 6 {
+		occupancy := genOccupancy(key, 6, bishopOccupancy[SA1])
+		expected := lookupBishopAttacks(SA1, occupancy)
+		if got := bishops.Attacks(SA1, occupancy); got != expected {
+			t.Fatalf("expected %016x got %016x", expected, got)
+		}
+	}
+
+	// The magic doesn't fit into the smaller table.
+	bishopBits[SA1] -= 2
+	if _, err := NewMagicTable(PieceWBishop, bishopMagics, bishopBits); err == nil {
+		t.Fatalf("expected destructive collisions")
+	}
+}
+
+func BenchmarkFindMagic(b *testing.B) {
+	r := rand.New(rand.NewPCG(1, 2))
+
+	for b.Loop() {
+		FindMagic(PieceWRook, SE4, rookBitCount[SE4], 1_000_000, r)
+	}
+}
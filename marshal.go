@@ -0,0 +1,293 @@
+/*
+marshal.go implements the text and JSON encodings of the core types.  Moves are
+encoded in UCI notation, positions as FEN strings and results by their names.
+
+The JSON representation of the [Game] has the following schema:
+
+	{
+	  "variant": "standard",
+	  "chess960": false,
+	  "startFen": "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1",
+	  "fen": "rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq - 0 1",
+	  "moves": [
+	    {"uci": "e2e4", "san": "e4", "fen": "...", "timeLeft": 180}
+	  ],
+	  "whiteTime": 180,
+	  "blackTime": 180,
+	  "timeBonus": 2,
+	  "captured": ["p", "N"],
+	  "result": {"reason": "resignation", "winner": "white"}
+	}
+
+The winner is either "white", "black" or "draw", and is omitted if the game
+isn't finished.
+*/
+
+package chego
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// resultNames maps each result to its text representation.
+var resultNames = [...]string{
+	"unscored", "checkmate", "timeout", "stalemate", "insufficient-material",
+	"fifty-move", "threefold-repetition", "resignation", "draw-by-agreement",
+	"three-check", "king-of-the-hill", "king-exploded", "antichess-win",
+	"horde-captured", "race-finished",
+}
+
+// winnerNames maps each color to the name of the winner in the JSON encoding.
+var winnerNames = [3]string{"white", "black", "draw"}
+
+// String returns the name of the result, e.g. "checkmate".
+func (r Result) String() string {
+	if r < 0 || int(r) >= len(resultNames) {
+		return fmt.Sprintf("Result(%d)", int(r))
+	}
+	return resultNames[r]
+}
+
+// MarshalText implements the [encoding.TextMarshaler] interface.
+func (r Result) MarshalText() ([]byte, error) {
+	if r < 0 || int(r) >= len(resultNames) {
+		return nil, fmt.Errorf("invalid result %d", int(r))
+	}
+	return []byte(resultNames[r]), nil
+}
+
+// UnmarshalText implements the [encoding.TextUnmarshaler] interface.
+func (r *Result) UnmarshalText(text []byte) error {
+	for i, name := range resultNames {
+		if name == string(text) {
+			*r = Result(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid result %q", text)
+}
+
+/*
+MarshalText implements the [encoding.TextMarshaler] interface.  The move is
+encoded in UCI notation, see [Move2UCI].
+*/
+func (m Move) MarshalText() ([]byte, error) {
+	return []byte(Move2UCI(m)), nil
+}
+
+/*
+UnmarshalText implements the [encoding.TextUnmarshaler] interface.  The move
+type cannot be detected without the position, hence castling and en passant
+moves are decoded as normal moves.  Use [UCI2Move] to decode the legal move in
+the specific position.
+*/
+func (m *Move) UnmarshalText(text []byte) error {
+	str := string(text)
+
+	// Drop move, e.g. N@f3.
+	if len(str) == 4 && str[1] == '@' {
+		to, err := SquareFromString(str[2:])
+		for piece := PieceWPawn; piece < PieceWKing && err == nil; piece += 2 {
+			if PieceSymbols[piece] == str[0] {
+				*m = NewDropMove(int(to), piece)
+				return nil
+			}
+		}
+		return fmt.Errorf("invalid move %q", str)
+	}
+
+	if len(str) != 4 && len(str) != 5 {
+		return fmt.Errorf("invalid move %q", str)
+	}
+	from, err := SquareFromString(str[:2])
+	if err != nil {
+		return fmt.Errorf("invalid move %q", str)
+	}
+	to, err := SquareFromString(str[2:4])
+	if err != nil {
+		return fmt.Errorf("invalid move %q", str)
+	}
+
+	if len(str) == 4 {
+		*m = NewMove(int(to), int(from), MoveNormal)
+		return nil
+	}
+
+	switch str[4] {
+	case 'n':
+		*m = NewPromotionMove(int(to), int(from), PromotionKnight)
+	case 'b':
+		*m = NewPromotionMove(int(to), int(from), PromotionBishop)
+	case 'r':
+		*m = NewPromotionMove(int(to), int(from), PromotionRook)
+	case 'q':
+		*m = NewPromotionMove(int(to), int(from), PromotionQueen)
+	case 'k':
+		*m = NewPromotionMove(int(to), int(from), PromotionKing)
+	default:
+		return fmt.Errorf("invalid move %q", str)
+	}
+	return nil
+}
+
+/*
+MarshalText implements the [encoding.TextMarshaler] interface.  The position
+is encoded as a FEN string, see [SerializeFEN].
+*/
+func (p Position) MarshalText() ([]byte, error) {
+	return []byte(SerializeFEN(p)), nil
+}
+
+/*
+UnmarshalText implements the [encoding.TextUnmarshaler] interface.  The FEN
+string is parsed with [ParseFEN], hence the variants which cannot be detected
+from the FEN string are decoded as the standard positions.
+*/
+func (p *Position) UnmarshalText(text []byte) error {
+	parsed, err := tryParseFEN(ParseFEN, string(text))
+	if err != nil {
+		return err
+	}
+	*p = parsed
+	return nil
+}
+
+/*
+tryParseFEN parses the FEN string with the specified function and returns an
+error instead of panicking if the FEN string is malformed.
+*/
+func tryParseFEN(parse func(string) Position, fen string) (p Position,
+	err error) {
+	defer func() {
+		if recover() != nil {
+			err = fmt.Errorf("invalid FEN string %q", fen)
+		}
+	}()
+	return parse(fen), nil
+}
+
+// gameJSON is the JSON representation of the [Game].
+type gameJSON struct {
+	Variant   string     `json:"variant"`
+	Chess960  bool       `json:"chess960"`
+	StartFEN  string     `json:"startFen"`
+	FEN       string     `json:"fen"`
+	Moves     []moveJSON `json:"moves"`
+	WhiteTime int        `json:"whiteTime"`
+	BlackTime int        `json:"blackTime"`
+	TimeBonus int        `json:"timeBonus"`
+	Captured  []string   `json:"captured"`
+	Result    resultJSON `json:"result"`
+}
+
+// moveJSON is the JSON representation of the [CompletedMove].
+type moveJSON struct {
+	UCI      string `json:"uci"`
+	SAN      string `json:"san"`
+	FEN      string `json:"fen"`
+	TimeLeft int    `json:"timeLeft"`
+}
+
+// resultJSON is the JSON representation of the game result and its winner.
+type resultJSON struct {
+	Reason Result `json:"reason"`
+	Winner string `json:"winner,omitempty"`
+}
+
+// MarshalJSON implements the [json.Marshaler] interface.
+func (g *Game) MarshalJSON() ([]byte, error) {
+	v := g.variant()
+
+	dto := gameJSON{
+		Variant:   v.Name(),
+		Chess960:  g.StartPosition.Chess960,
+		StartFEN:  v.SerializeFEN(g.StartPosition),
+		FEN:       v.SerializeFEN(g.Position),
+		Moves:     make([]moveJSON, len(g.MoveStack)),
+		WhiteTime: g.WhiteTime,
+		BlackTime: g.BlackTime,
+		TimeBonus: g.TimeBonus,
+		Captured:  make([]string, len(g.Captured)),
+		Result:    resultJSON{Reason: g.Result},
+	}
+
+	p := g.StartPosition
+	for i, completed := range g.MoveStack {
+		dto.Moves[i] = moveJSON{
+			UCI:      v.Move2UCI(p, completed.Move),
+			SAN:      Move2SAN(p, completed.Move, v),
+			FEN:      completed.FenString,
+			TimeLeft: completed.TimeLeft,
+		}
+		v.MakeMove(&p, completed.Move)
+	}
+
+	for i, piece := range g.Captured {
+		dto.Captured[i] = string(PieceSymbols[piece])
+	}
+
+	if g.Result != ResultUnscored {
+		if g.Winner < ColorWhite || g.Winner > ColorBoth {
+			return nil, fmt.Errorf("invalid winner %d", g.Winner)
+		}
+		dto.Result.Winner = winnerNames[g.Winner]
+	}
+
+	return json.Marshal(dto)
+}
+
+/*
+UnmarshalJSON implements the [json.Unmarshaler] interface.  The game is rebuilt
+by replaying the moves from the starting position, so the redundant fields,
+such as the FEN strings, SAN moves and captured pieces, are ignored.
+
+The game is played by the rules of the variant assigned to g.  If no variant is
+assigned, the built-in variant is looked up by its name.
+*/
+func (g *Game) UnmarshalJSON(data []byte) error {
+	var dto gameJSON
+	if err := json.Unmarshal(data, &dto); err != nil {
+		return err
+	}
+
+	v, err := resolveVariant(g.Variant, dto.Variant)
+	if err != nil {
+		return err
+	}
+
+	moves := make([]string, len(dto.Moves))
+	for i, move := range dto.Moves {
+		moves[i] = move.UCI
+	}
+	decoded, err := replayGame(v, dto.StartFEN, dto.Chess960, moves)
+	if err != nil {
+		return err
+	}
+	for i, move := range dto.Moves {
+		decoded.MoveStack[i].TimeLeft = move.TimeLeft
+	}
+
+	decoded.WhiteTime = dto.WhiteTime
+	decoded.BlackTime = dto.BlackTime
+	decoded.TimeBonus = dto.TimeBonus
+	decoded.Result = dto.Result.Reason
+
+	if dto.Result.Reason != ResultUnscored {
+		decoded.Winner = -1
+		for c, name := range winnerNames {
+			if name == dto.Result.Winner {
+				decoded.Winner = c
+			}
+		}
+		if decoded.Winner < 0 {
+			return fmt.Errorf("invalid winner %q", dto.Result.Winner)
+		}
+	}
+
+	if g.Clock != nil {
+		g.Clock.Stop()
+	}
+	*g = *decoded
+	return nil
+}
@@ -0,0 +1,207 @@
+package chego
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestResultText(t *testing.T) {
+	for r := ResultUnscored; r <= ResultRaceFinished; r++ {
+		text, err := r.MarshalText()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var got Result
+		if err := got.UnmarshalText(text); err != nil {
+			t.Fatal(err)
+		}
+		if got != r {
+			t.Fatalf("expected %s got %s", r, got)
+		}
+	}
+
+	var r Result
+	if err := r.UnmarshalText([]byte("mate")); err == nil {
+		t.Fatalf("expected error")
+	}
+}
+
+func TestMoveText(t *testing.T) {
+	testcases := []struct {
+		text     string
+		expected Move
+	}{
+		{"e2e4", NewMove(SE4, SE2, MoveNormal)},
+		{"e7e8n", NewPromotionMove(SE8, SE7, PromotionKnight)},
+		{"a2a1q", NewPromotionMove(SA1, SA2, PromotionQueen)},
+		{"e7e8k", NewPromotionMove(SE8, SE7, PromotionKing)},
+		{"N@f3", NewDropMove(SF3, PieceWKnight)},
+		{"P@e4", NewDropMove(SE4, PieceWPawn)},
+	}
+
+	for _, tc := range testcases {
+		var m Move
+		if err := m.UnmarshalText([]byte(tc.text)); err != nil {
+			t.Fatal(err)
+		}
+		if m != tc.expected {
+			t.Fatalf("%s: expected %d got %d", tc.text, tc.expected, m)
+		}
+
+		text, _ := m.MarshalText()
+		if string(text) != tc.text {
+			t.Fatalf("expected %s got %s", tc.text, text)
+		}
+	}
+
+	for _, text := range []string{"", "e2", "e2e9", "i2e4", "e7e8x", "K@e4"} {
+		var m Move
+		if err := m.UnmarshalText([]byte(text)); err == nil {
+			t.Fatalf("%s: expected error", text)
+		}
+	}
+}
+
+func TestPositionText(t *testing.T) {
+	fen := "r3k2r/ppp2ppp/8/8/8/8/PPP2PPP/R2Q~K2R[QNPnpp] b KQkq - 0 1"
+
+	var p Position
+	if err := p.UnmarshalText([]byte(fen)); err != nil {
+		t.Fatal(err)
+	}
+	if p != ParseFEN(fen) {
+		t.Fatalf("expected %s got %s", fen, SerializeFEN(p))
+	}
+
+	text, _ := p.MarshalText()
+	if string(text) != fen {
+		t.Fatalf("expected %s got %s", fen, text)
+	}
+
+	if err := p.UnmarshalText([]byte("8/8/8 w")); err == nil {
+		t.Fatalf("expected error")
+	}
+}
+
+func TestGameJSON(t *testing.T) {
+	g := NewGame()
+	g.WhiteTime, g.BlackTime, g.TimeBonus = 170, 160, 2
+	playUCI(t, g, operaGame)
+	for i := range g.MoveStack {
+		g.MoveStack[i].TimeLeft = 180 - i
+	}
+	g.Result, g.Winner = ResultCheckmate, ColorWhite
+
+	data, err := json.Marshal(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{
+		`"variant":"standard"`,
+		`"startFen":"` + InitialPos + `"`,
+		`{"uci":"e1c1","san":"O-O-O",`,
+		`{"uci":"d1d8","san":"Rd8#",`,
+		`"captured":["p","N","b","P","p","N","p","n","R","r","B","Q"]`,
+		`"result":{"reason":"checkmate","winner":"white"}`,
+	} {
+		if !strings.Contains(string(data), expected) {
+			t.Fatalf("expected %s in %s", expected, data)
+		}
+	}
+
+	var decoded Game
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+
+	if decoded.Position != g.Position ||
+		len(decoded.MoveStack) != len(g.MoveStack) {
+		t.Fatalf("expected %s got %s", SerializeFEN(g.Position),
+			SerializeFEN(decoded.Position))
+	}
+	for i := range g.MoveStack {
+		if decoded.MoveStack[i] != g.MoveStack[i] {
+			t.Fatalf("expected %v got %v at ply %d", g.MoveStack[i],
+				decoded.MoveStack[i], i+1)
+		}
+	}
+	if decoded.Result != g.Result || decoded.Winner != g.Winner ||
+		decoded.WhiteTime != g.WhiteTime || decoded.BlackTime != g.BlackTime ||
+		decoded.TimeBonus != g.TimeBonus ||
+		len(decoded.Captured) != len(g.Captured) {
+		t.Fatalf("game state is not restored")
+	}
+}
+
+func TestGameJSONVariant(t *testing.T) {
+	g := NewGame(Crazyhouse{})
+	playUCI(t, g, []string{"e2e4", "d7d5", "e4d5", "d8d5", "P@e4"})
+
+	data, err := json.Marshal(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded Game
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Variant.Name() != "crazyhouse" || decoded.Position != g.Position {
+		t.Fatalf("expected %s got %s", SerializeFEN(g.Position),
+			SerializeFEN(decoded.Position))
+	}
+
+	// The game cannot be decoded with the rules of the other variant.
+	if err := json.Unmarshal(data, &Game{Variant: Atomic{}}); err == nil {
+		t.Fatalf("expected error")
+	}
+}
+
+func TestGameJSONErrors(t *testing.T) {
+	testcases := []struct {
+		name string
+		data string
+	}{
+		{"unknown variant", `{"variant":"shogi"}`},
+		{"illegal move", `{"moves":[{"uci":"e2e5"}]}`},
+		{"invalid FEN", `{"startFen":"8/8/8 w"}`},
+		{"invalid result", `{"result":{"reason":"mate"}}`},
+		{"invalid winner", `{"result":{"reason":"resignation","winner":"nobody"}}`},
+	}
+
+	for _, tc := range testcases {
+		var g Game
+		if err := json.Unmarshal([]byte(tc.data), &g); err == nil {
+			t.Fatalf("%s: expected error", tc.name)
+		}
+	}
+}
+
+func TestCompletedMoveJSON(t *testing.T) {
+	completed := CompletedMove{
+		FenString: "4k3/8/8/8/8/8/8/4K3 b - - 0 1",
+		Move:      NewMove(SE4, SE2, MoveNormal),
+		TimeLeft:  60,
+	}
+
+	data, err := json.Marshal(completed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"fen":"4k3/8/8/8/8/8/8/4K3 b - - 0 1","move":"e2e4","timeLeft":60}`
+	if string(data) != expected {
+		t.Fatalf("expected %s got %s", expected, data)
+	}
+
+	var decoded CompletedMove
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded != completed {
+		t.Fatalf("expected %v got %v", completed, decoded)
+	}
+}
@@ -32,6 +32,31 @@ ONCE as close as possible to the start of your program.
 NOTE: Move generation will not work if the attack tables are not initialized.
 */
 func InitAttackTables() {
+	InitAttackTablesWith(SliderLookupMagic)
+}
+
+/*
+InitAttackTablesWith initializes the predefined attack tables, which will be
+indexed using the specified slider lookup method.  Use it instead of
+[InitAttackTables] to select the PEXT lookup on CPUs supporting BMI2 (see
+[HasBMI2]).
+
+Benchmark both methods on the target machine: the Go compiler cannot inline
+the assembly function executing PEXT, so the call overhead may outweigh the
+saved multiplication.
+
+NOTE: The attack tables are shared by both methods, so calling this function
+while moves are generated in other goroutines leads to data races.
+*/
+func InitAttackTablesWith(lookup SliderLookup) {
+	usePEXT := lookup == SliderLookupPEXT
+	lookupBishopAttacks = magicBishopAttacks
+	lookupRookAttacks = magicRookAttacks
+	if usePEXT {
+		lookupBishopAttacks = pextBishopAttacks
+		lookupRookAttacks = pextRookAttacks
+	}
+
 	initBishopOccupancy()
 	initRookOccupancy()
 
@@ -50,6 +75,10 @@ func InitAttackTables() {
 			occupancy := genOccupancy(i, bitCount, bishopOccupancy[square])
 
 			key := occupancy * bishopMagicNumbers[square] >> (64 - bitCount)
+			if usePEXT {
+				// PEXT reverses genOccupancy.
+				key = uint64(i)
+			}
 
 			bishopAttacks[square][key] = genBishopAttacks(bb, occupancy)
 		}
@@ -59,6 +88,9 @@ func InitAttackTables() {
 			occupancy := genOccupancy(i, bitCount, rookOccupancy[square])
 
 			key := occupancy * rookMagicNumbers[square] >> (64 - bitCount)
+			if usePEXT {
+				key = uint64(i)
+			}
 
 			rookAttacks[square][key] = genRookAttacks(bb, occupancy)
 		}
@@ -67,10 +99,25 @@ func InitAttackTables() {
 
 /*
 GenLegalMoves generates legal moves for the given position using copy-make
-approach.
+approach.  In Crazyhouse, drop moves are stored in the Drops of the list.
 */
 func GenLegalMoves(p Position, l *MoveList) {
 	l.LastMoveIndex = 0
+	l.Drops = l.Drops[:0]
+
+	switch p.Variant {
+	case VariantAtomic:
+		genAtomicMoves(p, l)
+		return
+	case VariantAntichess:
+		genAntichessMoves(p, l)
+		return
+	case VariantHorde:
+		if p.Bitboards[PieceWKing+p.ActiveColor] == 0 {
+			genHordeMoves(p, l)
+			return
+		}
+	}
 
 	genKingMoves(p, l)
 
@@ -78,24 +125,37 @@ func GenLegalMoves(p Position, l *MoveList) {
 		return
 	}
 
-	pseudoLegal := MoveList{}
+	// Pseudo-legal moves are appended to the move list and filtered in
+	// place to avoid copying the whole list.
+	legal := l.LastMoveIndex
 
-	genPawnMoves(p, &pseudoLegal)
+	genPawnMoves(p, l)
 
-	genNormalMoves(p, &pseudoLegal)
+	genNormalMoves(p, l)
 
 	prev := p
 
-	for i := range pseudoLegal.LastMoveIndex {
+	for i := legal; i < l.LastMoveIndex; i++ {
+		m := l.Moves[i]
 
-		p.MakeMove(pseudoLegal.Moves[i])
+		p.MakeMove(m)
 
 		if GenChecksCounter(p.Bitboards, 1^prev.ActiveColor) == 0 {
-			l.Push(pseudoLegal.Moves[i])
+			l.Moves[legal] = m
+			legal++
 		}
 
 		p = prev
 	}
+
+	l.LastMoveIndex = legal
+
+	switch p.Variant {
+	case VariantCrazyhouse:
+		genDropMoves(p, l)
+	case VariantRacingKings:
+		filterRacingKingsMoves(p, l)
+	}
 }
 
 /*
@@ -128,6 +188,46 @@ func GenChecksCounter(bitboards [15]uint64, c Color) (cnt int) {
 	return cnt
 }
 
+/*
+genCheckers returns a bitboard of the pieces of the specified color that are
+delivering a check to the enemy king.
+*/
+func genCheckers(bitboards [15]uint64, c Color) (checkers uint64) {
+	king := bitScan(bitboards[PieceWKing+(1^c)])
+	occupancy := bitboards[14]
+
+	checkers |= pawnAttacks[1^c][king] & bitboards[PieceWPawn+c]
+	checkers |= knightAttacks[king] & bitboards[PieceWKnight+c]
+	checkers |= lookupBishopAttacks(king, occupancy) &
+		(bitboards[PieceWBishop+c] | bitboards[PieceWQueen+c])
+	checkers |= lookupRookAttacks(king, occupancy) &
+		(bitboards[PieceWRook+c] | bitboards[PieceWQueen+c])
+
+	return checkers
+}
+
+/*
+genActiveCheckers returns a bitboard of the enemy pieces delivering a check to
+the king of the active player according to the rules of the position variant.
+*/
+func genActiveCheckers(p Position) uint64 {
+	king := p.Bitboards[PieceWKing+p.ActiveColor]
+	// There are no checks in Antichess.
+	if king == 0 || p.Variant == VariantAntichess {
+		return 0
+	}
+
+	// Adjacent kings cannot check each other in Atomic.
+	if p.Variant == VariantAtomic {
+		enemyKing := p.Bitboards[PieceWKing+(1^p.ActiveColor)]
+		if enemyKing == 0 || kingAttacks[bitScan(king)]&enemyKing != 0 {
+			return 0
+		}
+	}
+
+	return genCheckers(p.Bitboards, 1^p.ActiveColor)
+}
+
 /*
 genKingMoves appends legal moves for the king on the given position to the
 specified move list.  Handles special king move - castling.
@@ -145,26 +245,27 @@ func genKingMoves(p Position, l *MoveList) {
 		l.Push(NewMove(popLSB(&dests), king, MoveNormal))
 	}
 
-	p.Bitboards[14] ^= kingBB
 	// Handle castling.
-	if p.ActiveColor == ColorWhite {
-		if p.canCastle(CastlingWhiteShort, attacks, p.Bitboards[14]) &&
-			p.Bitboards[PieceWRook]&H1 != 0 {
-			l.Push(NewMove(SG1, king, MoveCastling))
-		}
-		if p.canCastle(CastlingWhiteLong, attacks, p.Bitboards[14]) &&
-			p.Bitboards[PieceWRook]&A1 != 0 {
-			l.Push(NewMove(SC1, king, MoveCastling))
-		}
-	} else {
-		if p.canCastle(CastlingBlackShort, attacks, p.Bitboards[14]) &&
-			p.Bitboards[PieceBRook]&H8 != 0 {
-			l.Push(NewMove(SG8, king, MoveCastling))
+	short, long := CastlingWhiteShort, CastlingWhiteLong
+	if p.ActiveColor == ColorBlack {
+		short, long = CastlingBlackShort, CastlingBlackLong
+	}
+
+	for _, side := range [2]CastlingRights{short, long} {
+		if !p.canCastle(side, attacks) {
+			continue
 		}
-		if p.canCastle(CastlingBlackLong, attacks, p.Bitboards[14]) &&
-			p.Bitboards[PieceBRook]&A8 != 0 {
-			l.Push(NewMove(SC8, king, MoveCastling))
+
+		m := p.newCastlingMove(side)
+		// See canCastle commentary.
+		if p.Chess960 {
+			after := p
+			after.MakeMove(m)
+			if GenChecksCounter(after.Bitboards, 1^p.ActiveColor) > 0 {
+				continue
+			}
 		}
+		l.Push(m)
 	}
 }
 
@@ -187,6 +288,9 @@ func genPawnMoves(p Position, l *MoveList) {
 		dir = -8
 		initRank = RANK_7
 		promoRank = RANK_1
+	} else if p.Variant == VariantHorde {
+		// White pawns on the first rank can move double forward as well.
+		initRank |= RANK_1
 	}
 
 	for pawns > 0 {
@@ -233,6 +337,57 @@ func genPawnMoves(p Position, l *MoveList) {
 	}
 }
 
+/*
+genDropMoves appends legal Crazyhouse drop moves to the Drops of the list.  Drops
+cannot expose the king to a check, so the only restriction is that the piece
+must block the check, if there is one.  Pawns cannot be dropped on the first
+and the eighth ranks.
+*/
+func genDropMoves(p Position, l *MoveList) {
+	c := p.ActiveColor
+	targets := ^p.Bitboards[14]
+
+	checkers := genCheckers(p.Bitboards, 1^c)
+	if checkers != 0 {
+		sliders := p.Bitboards[PieceWBishop+(1^c)] |
+			p.Bitboards[PieceWRook+(1^c)] | p.Bitboards[PieceWQueen+(1^c)]
+		// Double checks and checks by knights and pawns cannot be blocked.
+		if CountBits(checkers) > 1 || checkers&sliders == 0 {
+			return
+		}
+
+		king := bitScan(p.Bitboards[PieceWKing+c])
+		targets &= genBetween(king, bitScan(checkers), p.Bitboards[14])
+	}
+
+	for piece := PieceWPawn + c; piece <= PieceWQueen+c; piece += 2 {
+		if p.Pockets[piece] == 0 {
+			continue
+		}
+
+		dests := targets
+		if piece <= PieceBPawn {
+			dests &= NOT_1ST_RANK & NOT_8TH_RANK
+		}
+
+		for dests > 0 {
+			l.Drops = append(l.Drops, NewDropMove(popLSB(&dests), piece))
+		}
+	}
+}
+
+/*
+genBetween returns a bitboard of squares between a and b excluding both.  Both
+squares must lie on the same rank, file or diagonal, and there must be no
+pieces between them.
+*/
+func genBetween(a, b int, occupancy uint64) uint64 {
+	if a/8 == b/8 || a%8 == b%8 {
+		return lookupRookAttacks(a, occupancy) & lookupRookAttacks(b, occupancy)
+	}
+	return lookupBishopAttacks(a, occupancy) & lookupBishopAttacks(b, occupancy)
+}
+
 /*
 genPawnMoves appends pseudo-legal moves for knights, bishops, rooks, and queens
 to the given move list.
@@ -507,10 +662,10 @@ func genOccupancy(key, relevantBitCount int,
 }
 
 /*
-lookupBishopAttacks returns a bitboard of squares attacked by a bishop.  The
+magicBishopAttacks returns a bitboard of squares attacked by a bishop.  The
 bitboard is taken from the bishopAttacks using magic hashing scheme.
 */
-func lookupBishopAttacks(square int, occupancy uint64) uint64 {
+func magicBishopAttacks(square int, occupancy uint64) uint64 {
 	occupancy &= bishopOccupancy[square]
 	occupancy *= bishopMagicNumbers[square]
 	occupancy >>= 64 - bishopBitCount[square]
@@ -518,10 +673,10 @@ func lookupBishopAttacks(square int, occupancy uint64) uint64 {
 }
 
 /*
-lookupRookAttacks returns a bitboard of squares attacked by a rook.  The
+magicRookAttacks returns a bitboard of squares attacked by a rook.  The
 bitboard is taken from the rookAttacks using magic hashing scheme.
 */
-func lookupRookAttacks(square int, occupancy uint64) uint64 {
+func magicRookAttacks(square int, occupancy uint64) uint64 {
 	occupancy &= rookOccupancy[square]
 	occupancy *= rookMagicNumbers[square]
 	occupancy >>= 64 - rookBitCount[square]
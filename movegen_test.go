@@ -1,9 +1,75 @@
 package chego
 
 import (
+	"math/rand/v2"
 	"testing"
 )
 
+func TestPEXT(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+
+	for range 10000 {
+		x, mask := r.Uint64(), r.Uint64()
+
+		expected := uint64(0)
+		for i, bit := 0, 0; i < 64; i++ {
+			if mask&(1<<i) != 0 {
+				expected |= (x >> i & 1) << bit
+				bit++
+			}
+		}
+
+		if got := pextSoftware(x, mask); got != expected {
+			t.Fatalf("software: expected %x got %x", expected, got)
+		}
+		if got := pext(x, mask); got != expected {
+			t.Fatalf("expected %x got %x", expected, got)
+		}
+	}
+}
+
+func TestInitAttackTablesWith(t *testing.T) {
+	// Restore the default tables for other tests.
+	defer InitAttackTables()
+
+	r := rand.New(rand.NewPCG(1, 2))
+
+	occupancies := make([]uint64, 1000)
+	for i := range occupancies {
+		occupancies[i] = r.Uint64() & r.Uint64()
+	}
+
+	var bishop, rook [64][]uint64
+	for square := range 64 {
+		for _, occupancy := range occupancies {
+			bishop[square] = append(bishop[square],
+				lookupBishopAttacks(square, occupancy))
+			rook[square] = append(rook[square],
+				lookupRookAttacks(square, occupancy))
+		}
+	}
+
+	InitAttackTablesWith(SliderLookupPEXT)
+
+	for square := range 64 {
+		for i, occupancy := range occupancies {
+			if got := lookupBishopAttacks(square, occupancy); got != bishop[square][i] {
+				t.Fatalf("bishop on %s: expected %x got %x", Square2String[square],
+					bishop[square][i], got)
+			}
+			if got := lookupRookAttacks(square, occupancy); got != rook[square][i] {
+				t.Fatalf("rook on %s: expected %x got %x", Square2String[square],
+					rook[square][i], got)
+			}
+		}
+	}
+
+	pos := ParseFEN("r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1")
+	if got := Perft(pos, 3); got != 97862 {
+		t.Fatalf("expected 97862 nodes got %d\n%s", got, pos)
+	}
+}
+
 func BenchmarkGenPawnAttacks(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		genPawnAttacks(B4, ColorWhite)
@@ -64,6 +130,39 @@ func BenchmarkLookupQueenAttacks(b *testing.B) {
 	}
 }
 
+func BenchmarkLookupBishopAttacksPEXT(b *testing.B) {
+	InitAttackTablesWith(SliderLookupPEXT)
+	defer InitAttackTables()
+
+	for b.Loop() {
+		lookupBishopAttacks(35, 0x0)
+	}
+}
+
+func BenchmarkLookupRookAttacksPEXT(b *testing.B) {
+	InitAttackTablesWith(SliderLookupPEXT)
+	defer InitAttackTables()
+
+	for b.Loop() {
+		lookupRookAttacks(35, 0x0)
+	}
+}
+
+func BenchmarkLookupQueenAttacksPEXT(b *testing.B) {
+	InitAttackTablesWith(SliderLookupPEXT)
+	defer InitAttackTables()
+
+	for b.Loop() {
+		lookupQueenAttacks(35, 0x0)
+	}
+}
+
+func BenchmarkPEXTSoftware(b *testing.B) {
+	for b.Loop() {
+		pextSoftware(0x8100000000000000, rookOccupancy[SA1])
+	}
+}
+
 // func BenchmarkGenPawnMoves(b *testing.B) {
 // 	for i := 0; i < b.N; i++ {
 // 		genPawnMoves(SE4, 0x0, 0x0, 0, ColorWhite, &MoveList{})
@@ -87,6 +186,18 @@ func BenchmarkGenLegalMoves(b *testing.B) {
 	}
 }
 
+func BenchmarkGenLegalMovesPEXT(b *testing.B) {
+	InitAttackTablesWith(SliderLookupPEXT)
+	defer InitAttackTables()
+
+	pos := ParseFEN("r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1")
+
+	for b.Loop() {
+		lm := MoveList{}
+		GenLegalMoves(pos, &lm)
+	}
+}
+
 func BenchmarkInitAttackTables(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		InitAttackTables()
@@ -0,0 +1,144 @@
+/*
+perft.go implements performance test functions which walk through the move
+generation tree and count the visited leaf nodes.  Compare the results with
+predetermined values to find bugs in the move generation.
+
+See https://www.chessprogramming.org/Perft_Results
+*/
+
+package chego
+
+/*
+PerftStats stores the number of visited leaf nodes along with the detailed
+statistics about the moves leading to them.  Each category is counted at leaf
+nodes exactly as in the chessprogramming wiki perft tables.
+*/
+type PerftStats struct {
+	Nodes int
+	// Captures include en passant captures.
+	Captures   int
+	EnPassant  int
+	Castles    int
+	Promotions int
+	// Checks include discovered and double checks.
+	Checks int
+	// Single checks delivered by a piece other than the moved one.
+	// Double checks are not counted as discovered.
+	DiscoveredChecks int
+	DoubleChecks     int
+	Checkmates       int
+}
+
+/*
+Perft walks through the move generation tree of strictly legal moves to the
+given depth and returns the number of visited leaf nodes.  The moves are
+generated and performed by the specified variant, or by [Standard] if none is
+specified.
+*/
+func Perft(p Position, depth int, variant ...Variant) int {
+	return perft(optionalVariant(variant), p, depth)
+}
+
+// perft counts the leaf nodes of the move generation tree of the variant.
+func perft(v Variant, p Position, depth int) int {
+	if depth < 1 {
+		return 1
+	}
+
+	l := MoveList{}
+	nodes := 0
+
+	v.GenLegalMoves(p, &l)
+
+	if depth == 1 {
+		return l.Len()
+	}
+
+	prev := p
+	for m := range l.All() {
+		v.MakeMove(&p, m)
+
+		nodes += perft(v, p, depth-1)
+
+		p = prev
+	}
+
+	return nodes
+}
+
+/*
+PerftVerbose follows the same principle as the [Perft] function, except it
+collects detailed statistics about the leaf nodes.  Use this function to debug
+and find invalid branches in the move generation tree, not to measure
+performance.
+*/
+func PerftVerbose(p Position, depth int, variant ...Variant) (s PerftStats) {
+	if depth < 1 {
+		s.Nodes = 1
+		return s
+	}
+
+	perftVerbose(optionalVariant(variant), p, depth, &s)
+	return s
+}
+
+// perftVerbose accumulates the statistics of the leaf nodes in s.
+func perftVerbose(v Variant, p Position, depth int, s *PerftStats) {
+	l := MoveList{}
+
+	v.GenLegalMoves(p, &l)
+
+	prev := p
+	for m := range l.All() {
+		if depth > 1 {
+			v.MakeMove(&p, m)
+			perftVerbose(v, p, depth-1, s)
+			p = prev
+			continue
+		}
+
+		s.Nodes++
+
+		// Squares occupied by the moved pieces after the move.
+		moved := uint64(1 << m.To())
+
+		isCastling := m.Type() == MoveCastling
+
+		switch {
+		case m.Type() == MoveEnPassant:
+			s.EnPassant++
+			s.Captures++
+		case isCastling:
+			s.Castles++
+			kingTo, _, rookTo := p.castlingSquares(m)
+			moved = 1<<kingTo | 1<<rookTo
+		case m.Type() == MovePromotion:
+			s.Promotions++
+		}
+
+		if !isCastling && p.GetPieceFromSquare(moved) != PieceNone {
+			s.Captures++
+		}
+
+		v.MakeMove(&p, m)
+
+		checkers := genActiveCheckers(p)
+		if checkers != 0 {
+			s.Checks++
+
+			if CountBits(checkers) > 1 {
+				s.DoubleChecks++
+			} else if checkers&^moved != 0 {
+				s.DiscoveredChecks++
+			}
+
+			replies := MoveList{}
+			v.GenLegalMoves(p, &replies)
+			if replies.Len() == 0 {
+				s.Checkmates++
+			}
+		}
+
+		p = prev
+	}
+}
@@ -0,0 +1,69 @@
+package chego
+
+import "testing"
+
+func TestPerft(t *testing.T) {
+	testcases := []struct {
+		fen      string
+		depth    int
+		expected int
+	}{
+		{InitialPos, 4, 197281},
+		{"r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1", 3, 97862},
+		{"8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1", 4, 43238},
+		{"r3k2r/Pppp1ppp/1b3nbN/nP6/BBP1P3/q4N2/Pp1P2PP/R2Q1RK1 w kq - 0 1", 3, 9467},
+		{"rnbq1k1r/pp1Pbppp/2p5/8/2B5/8/PPP1NnPP/RNBQK2R w KQ - 1 8", 3, 62379},
+		{"r4rk1/1pp1qppp/p1np1n2/2b1p1B1/2B1P1b1/P1NP1N2/1PP1QPPP/R4RK1 w - - 0 10", 3, 89890},
+	}
+
+	for _, tc := range testcases {
+		p := ParseFEN(tc.fen)
+		if got := Perft(p, tc.depth); got != tc.expected {
+			t.Fatalf("depth %d: expected %d got %d\n%s", tc.depth,
+				tc.expected, got, p)
+		}
+	}
+}
+
+// Expected values are taken from https://www.chessprogramming.org/Perft_Results
+func TestPerftVerbose(t *testing.T) {
+	testcases := []struct {
+		name     string
+		fen      string
+		depth    int
+		expected PerftStats
+	}{
+		{"initial position", InitialPos, 3,
+			PerftStats{8902, 34, 0, 0, 0, 12, 0, 0, 0}},
+		{"initial position", InitialPos, 4,
+			PerftStats{197281, 1576, 0, 0, 0, 469, 0, 0, 8}},
+		{"kiwipete", "r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1", 1,
+			PerftStats{48, 8, 0, 2, 0, 0, 0, 0, 0}},
+		{"kiwipete", "r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1", 2,
+			PerftStats{2039, 351, 1, 91, 0, 3, 0, 0, 0}},
+		{"kiwipete", "r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1", 3,
+			PerftStats{97862, 17102, 45, 3162, 0, 993, 0, 0, 1}},
+		{"position 3", "8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1", 3,
+			PerftStats{2812, 209, 2, 0, 0, 267, 3, 0, 0}},
+		{"position 3", "8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1", 4,
+			PerftStats{43238, 3348, 123, 0, 0, 1680, 106, 0, 17}},
+		{"position 4", "r3k2r/Pppp1ppp/1b3nbN/nP6/BBP1P3/q4N2/Pp1P2PP/R2Q1RK1 w kq - 0 1", 2,
+			PerftStats{264, 87, 0, 6, 48, 10, 0, 0, 0}},
+	}
+
+	for _, tc := range testcases {
+		p := ParseFEN(tc.fen)
+		if got := PerftVerbose(p, tc.depth); got != tc.expected {
+			t.Fatalf("test \"%s\" depth %d failed: expected %+v\ngot %+v\n%s",
+				tc.name, tc.depth, tc.expected, got, p)
+		}
+	}
+}
+
+func BenchmarkPerft(b *testing.B) {
+	pos := ParseFEN("r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1")
+
+	for b.Loop() {
+		Perft(pos, 2)
+	}
+}
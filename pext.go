@@ -0,0 +1,73 @@
+/*
+pext.go implements an alternative slider attack lookup which compresses the
+relevant occupancy bits into the attack table index with the BMI2 PEXT
+instruction instead of the magic multiplication.  On machines lacking BMI2 a
+portable software implementation of PEXT is used.
+
+See https://www.chessprogramming.org/BMI2#PEXTBitboards
+*/
+
+package chego
+
+// SliderLookup defines the method used to index the slider attack tables.
+type SliderLookup int
+
+const (
+	// Multiply the relevant occupancy by the magic number and shift it.
+	SliderLookupMagic SliderLookup = iota
+	// Extract the relevant occupancy bits with PEXT.
+	SliderLookupPEXT
+)
+
+/*
+Slider attack lookups selected by InitAttackTablesWith, so the lookup method
+isn't checked on every call.
+*/
+var (
+	lookupBishopAttacks = magicBishopAttacks
+	lookupRookAttacks   = magicRookAttacks
+)
+
+/*
+HasBMI2 reports whether the CPU supports the BMI2 instruction set.  If it
+doesn't, the PEXT lookup falls back to the software implementation, which is
+considerably slower than the magic lookup.
+
+NOTE: AMD processors prior to Zen 3 implement PEXT in microcode, so the magic
+lookup is faster on them even though BMI2 is reported.
+*/
+func HasBMI2() bool {
+	return hasBMI2
+}
+
+/*
+pext extracts the bits of x selected by mask and packs them into the low-order
+bits of the result.
+*/
+func pext(x, mask uint64) uint64 {
+	if hasBMI2 {
+		return pextAsm(x, mask)
+	}
+	return pextSoftware(x, mask)
+}
+
+// pextBishopAttacks returns the bishop attacks indexed by PEXT.
+func pextBishopAttacks(square int, occupancy uint64) uint64 {
+	return bishopAttacks[square][pext(occupancy, bishopOccupancy[square])]
+}
+
+// pextRookAttacks returns the rook attacks indexed by PEXT.
+func pextRookAttacks(square int, occupancy uint64) uint64 {
+	return rookAttacks[square][pext(occupancy, rookOccupancy[square])]
+}
+
+// pextSoftware is a portable implementation of the PEXT instruction.
+func pextSoftware(x, mask uint64) (res uint64) {
+	for bit := uint64(1); mask != 0; bit <<= 1 {
+		if x&mask&-mask != 0 {
+			res |= bit
+		}
+		mask &= mask - 1
+	}
+	return res
+}
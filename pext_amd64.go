@@ -0,0 +1,24 @@
+package chego
+
+// Set once during the package initialization.
+var hasBMI2 = detectBMI2()
+
+// pextAsm executes the PEXT instruction.  Must only be called if hasBMI2 is true.
+//
+//go:noescape
+func pextAsm(x, mask uint64) uint64
+
+// cpuid executes the CPUID instruction with the specified leaf and subleaf.
+//
+//go:noescape
+func cpuid(leaf, subleaf uint32) (eax, ebx, ecx, edx uint32)
+
+// detectBMI2 checks the BMI2 bit of the extended CPU features.
+func detectBMI2() bool {
+	maxLeaf, _, _, _ := cpuid(0, 0)
+	if maxLeaf < 7 {
+		return false
+	}
+	_, ebx, _, _ := cpuid(7, 0)
+	return ebx&(1<<8) != 0
+}
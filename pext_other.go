@@ -0,0 +1,11 @@
+//go:build !amd64
+
+package chego
+
+// PEXT is only available on amd64.
+const hasBMI2 = false
+
+// pextAsm is never called on other architectures.
+func pextAsm(x, mask uint64) uint64 {
+	return pextSoftware(x, mask)
+}
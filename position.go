@@ -5,6 +5,8 @@ modifying the chessboard state.
 
 package chego
 
+import "iter"
+
 /*
 Position represents a chessboard state that can be converted to or parsed from
 a FEN string.
@@ -16,6 +18,26 @@ type Position struct {
 	EPTarget       int
 	HalfmoveCnt    int
 	FullmoveCnt    int
+	// Enables Chess960 (Fischer Random) castling rules.  Castling moves are
+	// encoded as the king capturing its own rook (see [MoveCastling]).
+	Chess960 bool
+	// Initial squares of the castling rooks indexed by the castling rights
+	// bits: white O-O, white O-O-O, black O-O, black O-O-O.  Used only if
+	// Chess960 is true, since in the standard chess rooks start in the
+	// corners.
+	CastlingRooks [4]int
+	// Built-in rules the position is played under.
+	Variant VariantKind
+	// Number of pieces in the players' pockets indexed by the piece type,
+	// e.g. Pockets[PieceBKnight] is the number of black knights in the black
+	// player's pocket.  Used only in Crazyhouse.
+	Pockets [10]uint8
+	// Bitboard of the pieces promoted from pawns.  Used only in Crazyhouse,
+	// since the captured promoted pieces go to the pocket as pawns.
+	Promoted uint64
+	// Number of checks delivered by each player indexed by color.  Used only
+	// in Three-check.
+	Checks [2]uint8
 }
 
 /*
@@ -27,10 +49,20 @@ castling rights, en passant target, halfmove counter, fullmove counter, and the
 active color.
 */
 func (p *Position) MakeMove(m Move) {
+	// There is no piece on the origin square of a drop move.
+	if m.IsDrop() {
+		p.makeDrop(m)
+		return
+	}
+
 	to := uint64(1 << m.To())
 	from := uint64(1 << m.From())
 	piece := p.GetPieceFromSquare(from)
-	captured := p.GetPieceFromSquare(to)
+	// Chess960 castling move targets the allied rook.
+	captured := PieceNone
+	if m.Type() != MoveCastling {
+		captured = p.GetPieceFromSquare(to)
+	}
 
 	// Clear the origin square.
 	p.removePiece(piece, from)
@@ -62,22 +94,12 @@ func (p *Position) MakeMove(m Move) {
 		}
 
 	case MoveCastling:
-		p.placePiece(piece, to)
-		// Update the rook position.
-		switch to {
-		case G1: // White O-O.
-			p.removePiece(PieceWRook, H1)
-			p.placePiece(PieceWRook, F1)
-		case G8: // Black O-O.
-			p.removePiece(PieceBRook, H8)
-			p.placePiece(PieceBRook, F8)
-		case C1: // White O-O-O.
-			p.removePiece(PieceWRook, A1)
-			p.placePiece(PieceWRook, D1)
-		case C8: // Black O-O-O.
-			p.removePiece(PieceBRook, A8)
-			p.placePiece(PieceBRook, D8)
-		}
+		kingTo, rookFrom, rookTo := p.castlingSquares(m)
+		// In Chess960 the king and the rook may swap places or stay on
+		// their squares, so the rook is removed before placing the pieces.
+		p.removePiece(PieceWRook+p.ActiveColor, 1<<rookFrom)
+		p.placePiece(piece, 1<<kingTo)
+		p.placePiece(PieceWRook+p.ActiveColor, 1<<rookTo)
 
 	case MovePromotion:
 		switch m.PromoPiece() {
@@ -89,13 +111,37 @@ func (p *Position) MakeMove(m Move) {
 			p.placePiece(PieceWRook+p.ActiveColor, to)
 		case PromotionQueen:
 			p.placePiece(PieceWQueen+p.ActiveColor, to)
+		case PromotionKing:
+			p.placePiece(PieceWKing+p.ActiveColor, to)
 		}
 	}
 
+	if p.Variant == VariantCrazyhouse {
+		p.updatePockets(m, captured)
+	}
+
+	if p.Variant == VariantAtomic &&
+		(captured != PieceNone || m.Type() == MoveEnPassant) {
+		p.explode(m.To())
+	}
+
 	// Reset the en passant target since the en passant capture
 	// is only legal for 1 move.
 	p.EPTarget = 0
 
+	// The king cannot castle with a rook that has already moved or has
+	// been captured.
+	if p.Chess960 {
+		for i := 0; p.CastlingRights>>i != 0; i++ {
+			rook := p.CastlingRooks[i]
+			if m.From() == rook || m.To() == rook {
+				p.CastlingRights &= ^(1 << i)
+			}
+		}
+	} else if p.CastlingRights != 0 {
+		p.CastlingRights &= castlingMasks[m.From()] & castlingMasks[m.To()]
+	}
+
 	switch piece {
 	// Set en passant target square is case of double pawn push.
 	case PieceWPawn, PieceBPawn:
@@ -106,22 +152,6 @@ func (p *Position) MakeMove(m Move) {
 		}
 		// Reset the halfmove counter after pawn moves.
 		p.HalfmoveCnt = 0
-	// The king cannot castle with a rook that has already moved.
-	case PieceWRook:
-		switch m.From() {
-		case SA1:
-			p.CastlingRights &= ^CastlingWhiteLong
-		case SH1:
-			p.CastlingRights &= ^CastlingWhiteShort
-		}
-	// The king cannot castle with a rook that has already moved.
-	case PieceBRook:
-		switch m.From() {
-		case SA8:
-			p.CastlingRights &= ^CastlingBlackLong
-		case SH8:
-			p.CastlingRights &= ^CastlingBlackShort
-		}
 	// Disable white castling rights.
 	case PieceWKing:
 		p.CastlingRights &= ^(CastlingWhiteShort | CastlingWhiteLong)
@@ -130,6 +160,11 @@ func (p *Position) MakeMove(m Move) {
 		p.CastlingRights &= ^(CastlingBlackShort | CastlingBlackLong)
 	}
 
+	if p.Variant == VariantThreeCheck &&
+		GenChecksCounter(p.Bitboards, p.ActiveColor) > 0 {
+		p.Checks[p.ActiveColor]++
+	}
+
 	// Increment the full move counter after black moves.
 	if p.ActiveColor == ColorBlack {
 		p.FullmoveCnt++
@@ -139,6 +174,58 @@ func (p *Position) MakeMove(m Move) {
 	p.ActiveColor ^= 1
 }
 
+/*
+makeDrop modifies the position by dropping the piece from the pocket of the
+active player onto the board.
+*/
+func (p *Position) makeDrop(m Move) {
+	piece := m.DropPiece(p.ActiveColor)
+
+	p.placePiece(piece, 1<<m.To())
+	p.Pockets[piece]--
+
+	p.EPTarget = 0
+
+	// Pawn drops are irreversible just like the pawn moves.
+	p.HalfmoveCnt++
+	if piece <= PieceBPawn {
+		p.HalfmoveCnt = 0
+	}
+
+	if p.ActiveColor == ColorBlack {
+		p.FullmoveCnt++
+	}
+
+	p.ActiveColor ^= 1
+}
+
+/*
+updatePockets puts the piece captured by the move into the pocket of the
+active player with the color flipped and keeps track of the promoted pieces.
+Must be called after the pieces are moved, but before the active color is
+switched.
+*/
+func (p *Position) updatePockets(m Move, captured Piece) {
+	to := uint64(1 << m.To())
+	from := uint64(1 << m.From())
+
+	switch {
+	case m.Type() == MoveEnPassant:
+		p.Pockets[PieceWPawn+p.ActiveColor]++
+	// Promoted pieces revert to pawns.
+	case captured != PieceNone && p.Promoted&to != 0:
+		p.Pockets[PieceWPawn+p.ActiveColor]++
+	case captured != PieceNone:
+		p.Pockets[captured&^1+p.ActiveColor]++
+	}
+
+	isPromoted := p.Promoted&from != 0 || m.Type() == MovePromotion
+	p.Promoted &^= from | to
+	if isPromoted {
+		p.Promoted |= to
+	}
+}
+
 /*
 GetPieceFromSquare returns the type of the piece that stands on the specified
 square, or [PieceNone] if the square is empty.
@@ -152,21 +239,124 @@ func (p *Position) GetPieceFromSquare(square uint64) Piece {
 	return PieceNone
 }
 
+/*
+Pieces returns an iterator over the occupied squares from A1 to H8 and the
+pieces standing on them.
+*/
+func (p *Position) Pieces() iter.Seq2[Square, Piece] {
+	return func(yield func(Square, Piece) bool) {
+		for square := range Bitboard(p.Bitboards[14]).Squares() {
+			if !yield(square, p.GetPieceFromSquare(uint64(square.Bitboard()))) {
+				return
+			}
+		}
+	}
+}
+
+/*
+castlingRook returns the initial square of the rook used in castling.  i is
+the index of the castling rights bit.
+*/
+func (p *Position) castlingRook(i int) int {
+	if p.Chess960 {
+		return p.CastlingRooks[i]
+	}
+	return standardCastlingRooks[i]
+}
+
+/*
+castlingSquares returns the destination square of the king and the initial and
+destination squares of the rook for the castling move.  After castling the king
+and the rook stand on the same squares as in the standard chess: g1 and f1 for
+O-O and c1 and d1 for O-O-O.
+*/
+func (p *Position) castlingSquares(m Move) (kingTo, rookFrom, rookTo int) {
+	rank := m.From() &^ 7
+
+	// Standard castling move targets the king destination square.
+	isShort := m.To()%8 == 6
+	rookFrom = rank + 7
+	if !isShort {
+		rookFrom = rank
+	}
+	// Chess960 castling move targets the rook square.
+	if p.Chess960 {
+		isShort = m.To() > m.From()
+		rookFrom = m.To()
+	}
+
+	if isShort {
+		return rank + 6, rookFrom, rank + 5
+	}
+	return rank + 2, rookFrom, rank + 3
+}
+
 /*
 canCastle checks whether the king can peform castling in the specified direction.
+attacks is a bitboard of squares attacked by the enemy pieces.
 
 side represents a castling type:
   - 1 -> White O-O.
   - 2 -> White O-O-O.
   - 4 -> Black O-O.
   - 8 -> Black O-O-O.
+
+NOTE: In Chess960, the enemy slider behind the castling rook may attack the
+king destination square after the rook leaves, so the caller must additionally
+verify that the king isn't in check after castling.
 */
-func (p *Position) canCastle(side int, attacks, occupancy uint64) bool {
+func (p *Position) canCastle(side int, attacks uint64) bool {
+	if p.CastlingRights&side == 0 {
+		return false
+	}
+
 	c := bitScan(uint64(side))
-	path := castlingPath[c]
-	return p.CastlingRights&side != 0 &&
-		attacks&castlingAttackPath[c] == 0 &&
-		occupancy&path == 0
+	rook := p.castlingRook(c)
+	if p.Bitboards[PieceWRook+p.ActiveColor]&(1<<rook) == 0 {
+		return false
+	}
+
+	king := bitScan(p.Bitboards[PieceWKing+p.ActiveColor])
+	// Destination squares of the king and the rook.
+	kingTo, rookTo := SG1, SF1
+	if side&(CastlingWhiteLong|CastlingBlackLong) != 0 {
+		kingTo, rookTo = SC1, SD1
+	}
+	kingTo += king &^ 7
+	rookTo += king &^ 7
+
+	kingPath := rankSpan(king, kingTo)
+	// All squares between the king and the rook and their destination
+	// squares must be empty, except the squares occupied by themselves.
+	occupancy := p.Bitboards[14] &^ (1<<king | 1<<rook)
+	return occupancy&(kingPath|rankSpan(rook, rookTo)) == 0 &&
+		attacks&kingPath == 0
+}
+
+/*
+newCastlingMove creates the castling move in the specified direction.  See
+[Position.canCastle] for the possible values of side.
+*/
+func (p *Position) newCastlingMove(side CastlingRights) Move {
+	king := bitScan(p.Bitboards[PieceWKing+p.ActiveColor])
+
+	if p.Chess960 {
+		return NewMove(p.castlingRook(bitScan(uint64(side))), king, MoveCastling)
+	}
+
+	if side&(CastlingWhiteLong|CastlingBlackLong) != 0 {
+		return NewMove(SC1+king&^7, king, MoveCastling)
+	}
+	return NewMove(SG1+king&^7, king, MoveCastling)
+}
+
+/*
+rankSpan returns a bitboard of squares between a and b including both.  Both
+squares must lie on the same rank.
+*/
+func rankSpan(a, b int) uint64 {
+	lo, hi := min(a, b), max(a, b)
+	return 1<<(hi+1) - 1<<lo
 }
 
 /*
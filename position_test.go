@@ -1,6 +1,9 @@
 package chego
 
-import "testing"
+import (
+	"slices"
+	"testing"
+)
 
 func TestMakeMove(t *testing.T) {
 	testcases := []struct {
@@ -83,7 +86,8 @@ func TestMakeMove(t *testing.T) {
 
 		got := SerializeFEN(pos)
 		if got != tc.expected {
-			t.Fatalf("test \"%s\" failed: expected %s got %s", tc.name, tc.expected, got)
+			t.Fatalf("test \"%s\" failed: expected %s got %s\n%s", tc.name,
+				tc.expected, got, pos)
 		}
 	}
 }
@@ -96,3 +100,24 @@ func BenchmarkMakeMove(b *testing.B) {
 		pos.MakeMove(NewMove(SG1, SE1, MoveCastling))
 	}
 }
+
+func TestPieces(t *testing.T) {
+	pos := ParseFEN("4k3/8/8/8/4P3/8/8/R3K3 w Q - 0 1")
+
+	type entry struct {
+		square Square
+		piece  Piece
+	}
+	expected := []entry{
+		{SA1, PieceWRook}, {SE1, PieceWKing}, {SE4, PieceWPawn}, {SE8, PieceBKing},
+	}
+
+	var got []entry
+	for square, piece := range pos.Pieces() {
+		got = append(got, entry{square, piece})
+	}
+
+	if !slices.Equal(got, expected) {
+		t.Fatalf("expected %v got %v", expected, got)
+	}
+}
@@ -185,21 +185,41 @@ var (
 		11, 10, 10, 10, 10, 10, 10, 11,
 		12, 11, 11, 11, 11, 11, 11, 12,
 	}
-	// Each path includes the king square.
-	// 0 : White O-O castling path.
-	// 1 : White O-O-O castling path.
-	// 2 : Black O-O castling path.
-	// 3 : Black O-O-O castling path.
-	castlingPath = [4]uint64{
-		0x70, 0x1E, 0x7000000000000000, 0x1E00000000000000,
-	}
-	castlingAttackPath = [4]uint64{
-		0x70, 0x1C, 0x7000000000000000, 0x1C00000000000000,
-	}
+	// Initial squares of the castling rooks in the standard chess indexed
+	// by the castling rights bits.
+	// 0 : White O-O rook.
+	// 1 : White O-O-O rook.
+	// 2 : Black O-O rook.
+	// 3 : Black O-O-O rook.
+	standardCastlingRooks = [4]int{SH1, SA1, SH8, SA8}
+	// Castling rights which remain after a piece leaves or enters the square
+	// in the standard chess.
+	castlingMasks = func() (masks [64]CastlingRights) {
+		for square := range masks {
+			masks[square] = 0xF
+		}
+		masks[SH1] &^= CastlingWhiteShort
+		masks[SA1] &^= CastlingWhiteLong
+		masks[SH8] &^= CastlingBlackShort
+		masks[SA8] &^= CastlingBlackLong
+		return masks
+	}()
 )
 
 const InitialPos = "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1"
 
+// Initial Antichess position without castling rights.
+const InitialAntichessPos = "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w - - 0 1"
+
+// Initial Horde position.
+const InitialHordePos = "rnbqkbnr/pppppppp/8/1PP2PP1/PPPPPPPP/PPPPPPPP/PPPPPPPP/PPPPPPPP w kq - 0 1"
+
+// Initial Racing Kings position.
+const InitialRacingKingsPos = "8/8/8/8/8/8/krbnNBRK/qrbnNBRQ w - - 0 1"
+
+// Initial Crazyhouse position with the empty pockets.
+const InitialCrazyhousePos = "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR[] w KQkq - 0 1"
+
 // Bitboards of each square.
 const (
 	A1 uint64 = 1 << iota
@@ -269,9 +289,10 @@ const (
 	ALL_SQUARES = 0xFFFFFFFFFFFFFFFF
 )
 
-// Indicies of each square.
+// Indicies of each square.  The constants are untyped, so they can be used
+// both as int and [Square] values.
 const (
-	SA1 int = iota
+	SA1 = iota
 	SB1
 	SC1
 	SD1
@@ -0,0 +1,34 @@
+/*
+racingkings.go implements the rules of Racing Kings.  Checks are forbidden, so
+neither king may move into check nor give it.  The player whose king reaches
+the eighth rank first wins.  If the white king reaches it first, black may
+still draw by reaching it on the next move.
+
+See https://lichess.org/variant/racingkings
+*/
+
+package chego
+
+/*
+filterRacingKingsMoves removes the legal moves which give check to the enemy
+king from the given move list.
+*/
+func filterRacingKingsMoves(p Position, l *MoveList) {
+	legal := byte(0)
+	prev := p
+
+	for i := range l.LastMoveIndex {
+		m := l.Moves[i]
+
+		p.MakeMove(m)
+
+		if GenChecksCounter(p.Bitboards, prev.ActiveColor) == 0 {
+			l.Moves[legal] = m
+			legal++
+		}
+
+		p = prev
+	}
+
+	l.LastMoveIndex = legal
+}
@@ -0,0 +1,66 @@
+package chego
+
+import "testing"
+
+// Expected values are taken from the lichess perft suite.
+func TestRacingKingsPerft(t *testing.T) {
+	p := ParseVariantFEN(InitialRacingKingsPos, VariantRacingKings)
+	expected := []int{21, 421, 11264, 296242}
+
+	for i, nodes := range expected {
+		if got := Perft(p, i+1); got != nodes {
+			t.Fatalf("depth %d: expected %d got %d\n%s", i+1, nodes, got, p)
+		}
+	}
+}
+
+func TestFilterRacingKingsMoves(t *testing.T) {
+	// The king cannot discover a check by the rook.
+	p := ParseVariantFEN("k7/8/8/8/8/8/K7/R7 w - - 0 1", VariantRacingKings)
+	l := MoveList{}
+	GenLegalMoves(p, &l)
+
+	for m := range l.All() {
+		switch Move2UCI(m) {
+		case "a2b1", "a2b2", "a2b3":
+			t.Fatalf("move %s gives check\n%s", Move2UCI(m), p)
+		}
+	}
+	// 1 king move and 7 rook moves along the first rank.
+	if l.LastMoveIndex != 8 {
+		t.Fatalf("expected 8 moves got %d", l.LastMoveIndex)
+	}
+
+	// The rook cannot give a check directly.
+	p = ParseVariantFEN("k7/8/8/8/8/8/8/1R5K w - - 0 1", VariantRacingKings)
+	GenLegalMoves(p, &l)
+	for m := range l.All() {
+		if Move2UCI(m) == "b1a1" || Move2UCI(m) == "b1b8" {
+			t.Fatalf("move %s gives check\n%s", Move2UCI(m), p)
+		}
+	}
+}
+
+func TestIsRaceFinished(t *testing.T) {
+	testcases := []struct {
+		name     string
+		fen      string
+		expected bool
+	}{
+		{"initial position", InitialRacingKingsPos, false},
+		{"black wins", "1k6/8/K7/8/8/8/8/8 w - - 0 1", true},
+		{"black equalizes", "7K/k7/8/8/8/8/8/8 b - - 0 1", false},
+		{"black is too far", "7K/8/k7/8/8/8/8/8 b - - 0 1", true},
+		{"draw", "K6k/8/8/8/8/8/8/8 w - - 0 1", true},
+	}
+
+	for _, tc := range testcases {
+		g := NewGame(RacingKings{})
+		g.Position = ParseVariantFEN(tc.fen, VariantRacingKings)
+		GenLegalMoves(g.Position, &g.LegalMoves)
+
+		if got := g.IsRaceFinished(); got != tc.expected {
+			t.Fatalf("%s: expected %t got %t", tc.name, tc.expected, got)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+// gif.go implements the animated GIF replays of the games.
+
+package render
+
+import (
+	"cmp"
+	"image"
+	"image/color"
+	"image/gif"
+	"io"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/BelikovArtem/chego"
+)
+
+/*
+GIF replays the game from its starting position and writes it to w as an
+animated GIF image.  Each frame shows the position after the move from the
+game's MoveStack with the move highlighted, and is displayed for the delay.
+The final position is held for the hold duration before the animation loops.
+
+The LastMove option is overridden by the replayed moves; the other options,
+including the annotations, apply to every frame.
+*/
+func GIF(w io.Writer, g *chego.Game, opts Options, delay,
+	hold time.Duration) error {
+	var v chego.Variant = chego.Standard{}
+	if g.Variant != nil {
+		v = g.Variant
+	}
+
+	anim := &gif.GIF{
+		Image: make([]*image.Paletted, 0, len(g.MoveStack)+1),
+		Delay: make([]int, 0, len(g.MoveStack)+1),
+	}
+	addFrame := func(p chego.Position) {
+		anim.Image = append(anim.Image, quantize(Image(p, opts)))
+		anim.Delay = append(anim.Delay, int(delay/(10*time.Millisecond)))
+	}
+
+	p := g.StartPosition
+	opts.LastMove = 0
+	addFrame(p)
+	for _, completed := range g.MoveStack {
+		v.MakeMove(&p, completed.Move)
+		opts.LastMove = completed.Move
+		addFrame(p)
+	}
+	anim.Delay[len(anim.Delay)-1] = int(hold / (10 * time.Millisecond))
+
+	return gif.EncodeAll(w, anim)
+}
+
+/*
+quantize converts the image to the paletted one.  The palette consists of the
+256 most frequent colors of the image, which include the board and piece
+colors; the rare anti-aliased colors are mapped to the nearest palette colors.
+*/
+func quantize(img *image.RGBA) *image.Paletted {
+	counts := make(map[color.RGBA]int)
+	for i := 0; i < len(img.Pix); i += 4 {
+		counts[color.RGBA{img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3]}]++
+	}
+
+	colors := slices.Collect(maps.Keys(counts))
+	// Break the ties by the color value to keep the palette deterministic.
+	slices.SortFunc(colors, func(a, b color.RGBA) int {
+		if c := cmp.Compare(counts[b], counts[a]); c != 0 {
+			return c
+		}
+		return cmp.Compare(packRGBA(a), packRGBA(b))
+	})
+
+	palette := make(color.Palette, min(len(colors), 256))
+	index := make(map[color.RGBA]uint8, len(colors))
+	for i, c := range colors {
+		if i < len(palette) {
+			palette[i] = c
+			index[c] = uint8(i)
+		} else {
+			index[c] = uint8(palette.Index(c))
+		}
+	}
+
+	out := image.NewPaletted(img.Bounds(), palette)
+	for i := range out.Pix {
+		px := img.Pix[4*i : 4*i+4]
+		out.Pix[i] = index[color.RGBA{px[0], px[1], px[2], px[3]}]
+	}
+	return out
+}
+
+// packRGBA packs the color into a single integer.
+func packRGBA(c color.RGBA) uint32 {
+	return uint32(c.R)<<24 | uint32(c.G)<<16 | uint32(c.B)<<8 | uint32(c.A)
+}
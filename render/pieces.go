@@ -0,0 +1,91 @@
+// pieces.go defines the piece set drawn on the board images.
+
+package render
+
+// Point is a point in the coordinate space of the board image.
+type Point struct {
+	X, Y float64
+}
+
+/*
+Shape is a filled and outlined polygon or circle.  The shapes of the pieces are
+defined in a 45x45 box with the origin in the top left corner.
+*/
+type Shape struct {
+	// Vertices of the polygon.  Empty for the circle.
+	Points []Point
+	Center Point
+	Radius float64
+}
+
+// polygon creates a polygon shape from the pairs of coordinates.
+func polygon(coords ...float64) Shape {
+	s := Shape{Points: make([]Point, len(coords)/2)}
+	for i := range s.Points {
+		s.Points[i] = Point{coords[2*i], coords[2*i+1]}
+	}
+	return s
+}
+
+// circle creates a circle shape.
+func circle(x, y, r float64) Shape {
+	return Shape{Center: Point{x, y}, Radius: r}
+}
+
+/*
+PieceSet holds the shapes of each piece type indexed by the piece type divided
+by two: pawn, knight, bishop, rook, queen and king.  The shapes are drawn in
+order, so the later shapes overlap the earlier ones.  The same shapes are used
+for both colors.
+*/
+type PieceSet [6][]Shape
+
+// base is the pedestal shared by most pieces.
+var base = polygon(10, 35, 35, 35, 35, 40, 10, 40)
+
+// DefaultPieces is the built-in set of the simple geometric pieces.
+var DefaultPieces = PieceSet{
+	// Pawn.
+	{
+		polygon(18, 19, 27, 19, 30, 35, 15, 35),
+		polygon(11, 35, 34, 35, 34, 39, 11, 39),
+		circle(22.5, 14, 6.5),
+	},
+	// Knight.
+	{
+		polygon(13, 40, 36, 40, 35, 30, 34, 20, 31, 13, 26, 8, 22, 5, 21, 9,
+			17, 10, 12, 16, 8, 23, 10, 27, 14, 26, 18, 23, 22, 22, 16, 31),
+	},
+	// Bishop.
+	{
+		base,
+		polygon(16, 31, 29, 31, 30, 35, 15, 35),
+		polygon(22.5, 10, 28, 15, 30, 22, 27, 31, 18, 31, 15, 22, 17, 15),
+		circle(22.5, 8, 3),
+	},
+	// Rook.
+	{
+		base,
+		polygon(14, 16, 31, 16, 31, 35, 14, 35),
+		polygon(11, 8, 16, 8, 16, 11, 20, 11, 20, 8, 25, 8, 25, 11, 29, 11,
+			29, 8, 34, 8, 34, 16, 11, 16),
+	},
+	// Queen.
+	{
+		base,
+		polygon(9, 13, 14, 27, 16, 12, 20, 26, 22.5, 10, 25, 26, 29, 12,
+			31, 27, 36, 13, 32, 35, 13, 35),
+		circle(9, 12, 2.5),
+		circle(16, 11, 2.5),
+		circle(22.5, 9, 2.5),
+		circle(29, 11, 2.5),
+		circle(36, 12, 2.5),
+	},
+	// King.
+	{
+		base,
+		polygon(14, 18, 31, 18, 33, 23, 30, 35, 15, 35, 12, 23),
+		polygon(21, 4, 24, 4, 24, 8, 28, 8, 28, 11, 24, 11, 24, 18, 21, 18,
+			21, 11, 17, 11, 17, 8, 21, 8),
+	},
+}
@@ -0,0 +1,292 @@
+/*
+raster.go implements the raster backend of the board renderer: an anti-aliased
+scanline rasterizer of the scene elements built on the standard library image
+packages.
+*/
+
+package render
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+	"slices"
+
+	"github.com/BelikovArtem/chego"
+)
+
+// subsamples is the number of the sample rows per pixel used for anti-aliasing.
+const subsamples = 4
+
+/*
+Image renders the position as an RGBA image of the size specified in the
+options.
+*/
+func Image(p chego.Position, opts Options) *image.RGBA {
+	size := opts.size()
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	scale := float64(size) / boardSize
+
+	for _, e := range scene(p, opts) {
+		fill, stroke := rasterPaths(e, scale)
+		if e.fill.A > 0 {
+			newMask(img.Bounds(), fill).draw(img, e.fill)
+		}
+		if len(stroke) > 0 && e.stroke.A > 0 {
+			newMask(img.Bounds(), stroke...).draw(img, e.stroke)
+		}
+	}
+
+	return img
+}
+
+// PNG renders the position and writes it to w as a PNG image.
+func PNG(w io.Writer, p chego.Position, opts Options) error {
+	return png.Encode(w, Image(p, opts))
+}
+
+/*
+path is a set of closed contours in the image coordinates filled by the
+even-odd rule.
+*/
+type path [][]Point
+
+/*
+rasterPaths converts the element into the paths of its fill and stroke.  The
+overlapping stroke paths are combined by union.
+*/
+func rasterPaths(e element, scale float64) (fill path, stroke []path) {
+	scaled := func(p Point) Point { return Point{p.X * scale, p.Y * scale} }
+	halfWidth := e.strokeWidth * scale / 2
+
+	switch {
+	case e.text != "":
+		fill = textPath(e.text, scaled(e.Center), e.textSize*scale)
+
+	case len(e.Points) > 0:
+		contour := make([]Point, len(e.Points))
+		for i, p := range e.Points {
+			contour[i] = scaled(p)
+		}
+		fill = path{contour}
+
+		if halfWidth > 0 {
+			for i, a := range contour {
+				b := contour[(i+1)%len(contour)]
+				stroke = append(stroke, path{segment(a, b, halfWidth)},
+					path{circlePath(a, halfWidth)})
+			}
+		}
+
+	case e.Radius > 0:
+		center, r := scaled(e.Center), e.Radius*scale
+		fill = path{circlePath(center, r)}
+
+		if halfWidth > 0 {
+			stroke = append(stroke, path{circlePath(center, r+halfWidth),
+				circlePath(center, max(r-halfWidth, 0))})
+		}
+	}
+
+	return fill, stroke
+}
+
+// circlePath approximates the circle with a polygon.
+func circlePath(center Point, r float64) []Point {
+	n := min(max(int(r*2), 16), 128)
+	points := make([]Point, n)
+	for i := range points {
+		angle := 2 * math.Pi * float64(i) / float64(n)
+		points[i] = Point{center.X + r*math.Cos(angle),
+			center.Y + r*math.Sin(angle)}
+	}
+	return points
+}
+
+// segment returns the rectangle covering the line segment of the given width.
+func segment(a, b Point, halfWidth float64) []Point {
+	length := math.Hypot(b.X-a.X, b.Y-a.Y)
+	if length == 0 {
+		return nil
+	}
+	nx := -(b.Y - a.Y) / length * halfWidth
+	ny := (b.X - a.X) / length * halfWidth
+	return []Point{{a.X + nx, a.Y + ny}, {b.X + nx, b.Y + ny},
+		{b.X - nx, b.Y - ny}, {a.X - nx, a.Y - ny}}
+}
+
+// mask is the coverage of the element over a rectangle of the image.
+type mask struct {
+	rect image.Rectangle
+	// Coverage of each pixel of the rectangle in the range [0, 1].
+	cov []float32
+}
+
+/*
+newMask rasterizes the union of the paths clipped by the image bounds.  The
+coverage of the overlapping paths is combined by taking the maximum.
+*/
+func newMask(bounds image.Rectangle, paths ...path) *mask {
+	rect := image.Rectangle{}
+	for _, p := range paths {
+		for _, contour := range p {
+			for _, pt := range contour {
+				r := image.Rect(int(math.Floor(pt.X)), int(math.Floor(pt.Y)),
+					int(math.Ceil(pt.X))+1, int(math.Ceil(pt.Y))+1)
+				rect = rect.Union(r)
+			}
+		}
+	}
+	rect = rect.Intersect(bounds)
+
+	m := &mask{rect: rect, cov: make([]float32, rect.Dx()*rect.Dy())}
+	row := make([]float32, rect.Dx())
+	var xs []float64
+
+	for _, p := range paths {
+		for y := rect.Min.Y; y < rect.Max.Y; y++ {
+			clear(row)
+			for j := range subsamples {
+				sy := float64(y) + (float64(j)+0.5)/subsamples
+
+				xs = xs[:0]
+				for _, contour := range p {
+					for i, a := range contour {
+						b := contour[(i+1)%len(contour)]
+						if (a.Y <= sy) != (b.Y <= sy) {
+							xs = append(xs, a.X+(sy-a.Y)*(b.X-a.X)/(b.Y-a.Y))
+						}
+					}
+				}
+				slices.Sort(xs)
+
+				for k := 0; k+1 < len(xs); k += 2 {
+					addSpan(row, xs[k]-float64(rect.Min.X),
+						xs[k+1]-float64(rect.Min.X))
+				}
+			}
+
+			cov := m.cov[(y-rect.Min.Y)*rect.Dx():]
+			for i, c := range row {
+				cov[i] = max(cov[i], min(c, 1))
+			}
+		}
+	}
+
+	return m
+}
+
+/*
+addSpan adds the coverage of the horizontal span [x0, x1) of a single sample
+row to the pixel row.  The pixels partially covered by the span receive the
+fractional coverage.
+*/
+func addSpan(row []float32, x0, x1 float64) {
+	const weight = 1.0 / subsamples
+
+	x0, x1 = max(x0, 0), min(x1, float64(len(row)))
+	if x0 >= x1 {
+		return
+	}
+
+	i0, i1 := int(x0), int(x1)
+	if i0 == i1 {
+		row[i0] += weight * float32(x1-x0)
+		return
+	}
+	row[i0] += weight * float32(float64(i0+1)-x0)
+	for i := i0 + 1; i < i1; i++ {
+		row[i] += weight
+	}
+	if i1 < len(row) {
+		row[i1] += weight * float32(x1-float64(i1))
+	}
+}
+
+// draw blends the color into the image weighted by the mask coverage.
+func (m *mask) draw(img *image.RGBA, c color.NRGBA) {
+	for y := m.rect.Min.Y; y < m.rect.Max.Y; y++ {
+		cov := m.cov[(y-m.rect.Min.Y)*m.rect.Dx():]
+		pix := img.Pix[img.PixOffset(m.rect.Min.X, y):]
+
+		for x := range m.rect.Dx() {
+			if cov[x] == 0 {
+				continue
+			}
+			alpha := float32(c.A) / 0xff * cov[x]
+			px := pix[4*x : 4*x+4]
+			px[0] = blend(c.R, px[0], alpha)
+			px[1] = blend(c.G, px[1], alpha)
+			px[2] = blend(c.B, px[2], alpha)
+			px[3] = blend(0xff, px[3], alpha)
+		}
+	}
+}
+
+// blend composites the source channel over the premultiplied destination one.
+func blend(src, dst uint8, alpha float32) uint8 {
+	return uint8(float32(src)*alpha + float32(dst)*(1-alpha) + 0.5)
+}
+
+/*
+glyphs is the bitmap font of the board coordinates.  Each glyph is 5 pixels
+wide and 7 pixels tall, with the baseline at the bottom row.
+*/
+var glyphs = map[rune][7]string{
+	'a': {".....", ".....", ".###.", "....#", ".####", "#...#", ".####"},
+	'b': {"#....", "#....", "####.", "#...#", "#...#", "#...#", "####."},
+	'c': {".....", ".....", ".###.", "#....", "#....", "#....", ".###."},
+	'd': {"....#", "....#", ".####", "#...#", "#...#", "#...#", ".####"},
+	'e': {".....", ".....", ".###.", "#...#", "#####", "#....", ".###."},
+	'f': {"..##.", ".#...", "####.", ".#...", ".#...", ".#...", ".#..."},
+	'g': {".....", ".####", "#...#", "#...#", ".####", "....#", ".###."},
+	'h': {"#....", "#....", "####.", "#...#", "#...#", "#...#", "#...#"},
+	'1': {"..#..", ".##..", "..#..", "..#..", "..#..", "..#..", ".###."},
+	'2': {".###.", "#...#", "....#", "...#.", "..#..", ".#...", "#####"},
+	'3': {".###.", "#...#", "....#", "..##.", "....#", "#...#", ".###."},
+	'4': {"...#.", "..##.", ".#.#.", "#..#.", "#####", "...#.", "...#."},
+	'5': {"#####", "#....", "####.", "....#", "....#", "#...#", ".###."},
+	'6': {".###.", "#....", "#....", "####.", "#...#", "#...#", ".###."},
+	'7': {"#####", "....#", "...#.", "..#..", ".#...", ".#...", ".#..."},
+	'8': {".###.", "#...#", "#...#", ".###.", "#...#", "#...#", ".###."},
+}
+
+/*
+textPath returns the path of the text drawn with the bitmap font.  The glyph
+pixel is one ninth of the font size, which roughly matches the cap height of
+the sans-serif fonts.  The runes missing from the font are skipped.
+*/
+func textPath(text string, origin Point, size float64) path {
+	unit := size / 9
+	var p path
+
+	x := origin.X
+	for _, r := range text {
+		glyph, ok := glyphs[r]
+		if !ok {
+			continue
+		}
+		for row, line := range glyph {
+			y := origin.Y - float64(7-row)*unit
+			// Merge the horizontal runs of pixels into the rectangles.
+			for start := 0; start < len(line); start++ {
+				if line[start] != '#' {
+					continue
+				}
+				end := start
+				for end < len(line) && line[end] == '#' {
+					end++
+				}
+				x0, x1 := x+float64(start)*unit, x+float64(end)*unit
+				p = append(p, []Point{{x0, y}, {x1, y}, {x1, y + unit},
+					{x0, y + unit}})
+				start = end
+			}
+		}
+		x += 6 * unit
+	}
+
+	return p
+}
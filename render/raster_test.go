@@ -0,0 +1,110 @@
+package render
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"testing"
+	"time"
+
+	"github.com/BelikovArtem/chego"
+)
+
+func TestImage(t *testing.T) {
+	start := chego.ParseFEN(chego.InitialPos)
+	e2e4 := chego.NewMove(chego.SE4, chego.SE2, chego.MoveNormal)
+
+	testcases := []struct {
+		name     string
+		opts     Options
+		x, y     int
+		expected color.RGBA
+	}{
+		{"light square", Options{}, 47, 358, color.RGBA{0xf0, 0xd9, 0xb5, 0xff}},
+		{"dark square", Options{}, 2, 358, color.RGBA{0xb5, 0x88, 0x63, 0xff}},
+		{"scaled", Options{Size: 720}, 4, 716, color.RGBA{0xb5, 0x88, 0x63, 0xff}},
+		// The white pawn on a2 is in the top right corner.
+		{"flipped", Options{Flipped: true}, 337, 75, color.RGBA{0xff, 0xff, 0xff, 0xff}},
+		// Center of the white pawn body on a2.
+		{"white piece", Options{}, 22, 300, color.RGBA{0xff, 0xff, 0xff, 0xff}},
+		// Center of the black rook body on a8.
+		{"black piece", Options{}, 22, 25, color.RGBA{0x00, 0x00, 0x00, 0xff}},
+		// Light e4 square blended with the translucent highlight.
+		{"last move", Options{LastMove: e2e4}, 182, 182, color.RGBA{0xcd, 0xd2, 0x6a, 0xff}},
+		{"custom color", Options{DarkColor: color.Black}, 2, 358, color.RGBA{0, 0, 0, 0xff}},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			img := Image(start, tc.opts)
+			if got := img.RGBAAt(tc.x, tc.y); got != tc.expected {
+				t.Fatalf("expected: %v, got: %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestPNG(t *testing.T) {
+	var buf bytes.Buffer
+	err := PNG(&buf, chego.ParseFEN(chego.InitialPos), Options{Size: 200})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.Bounds() != image.Rect(0, 0, 200, 200) {
+		t.Fatalf("expected 200x200 image, got: %v", img.Bounds())
+	}
+}
+
+func TestGIF(t *testing.T) {
+	g := chego.NewGame()
+	// Fool's mate.
+	for _, uci := range []string{"f2f3", "e7e5", "g2g4", "d8h4"} {
+		m, err := chego.UCI2Move(g.Position, uci)
+		if err != nil {
+			t.Fatal(err)
+		}
+		g.PushMove(m)
+	}
+
+	var buf bytes.Buffer
+	err := GIF(&buf, g, Options{Size: 120, Check: true}, 500*time.Millisecond,
+		3*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	anim, err := gif.DecodeAll(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []int{50, 50, 50, 50, 300}
+	if len(anim.Delay) != len(expected) {
+		t.Fatalf("expected %d frames, got: %d", len(expected), len(anim.Delay))
+	}
+	for i, d := range expected {
+		if anim.Delay[i] != d {
+			t.Fatalf("frame %d: expected delay %d, got: %d", i, d, anim.Delay[i])
+		}
+	}
+
+	// The board colors must survive the quantization.
+	dark := color.RGBA{0xb5, 0x88, 0x63, 0xff}
+	if got := color.RGBAModel.Convert(anim.Image[0].At(1, 118)); got != dark {
+		t.Fatalf("expected: %v, got: %v", dark, got)
+	}
+}
+
+func BenchmarkImage(b *testing.B) {
+	p := chego.ParseFEN(chego.InitialPos)
+	opts := Options{Coordinates: true}
+	for b.Loop() {
+		Image(p, opts)
+	}
+}
@@ -0,0 +1,277 @@
+/*
+Package render draws chess positions as images.  The board is composed of the
+simple shapes, which are written as SVG elements or rasterized, so the images
+don't depend on the fonts or external tools.
+
+Make sure to call [chego.InitAttackTables] ONCE before rendering the positions
+with the check highlight.
+*/
+package render
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/BelikovArtem/chego"
+)
+
+// boardSize is the size of the board in the scene units: 45 units per square.
+const boardSize = 8 * squareSize
+
+const squareSize = 45
+
+/*
+Options configures the board image.  The zero value draws a 360x360 board from
+the white player's side with the default colors and pieces.
+*/
+type Options struct {
+	// Width and height of the image in pixels.  360 by default.
+	Size int
+	// Draw the board from the black player's side.
+	Flipped bool
+	// Draw the file letters and the rank numbers.
+	Coordinates bool
+	// Colors of the light and dark squares, the last move highlight and the
+	// check highlight.  The default colors are used if nil.
+	LightColor     color.Color
+	DarkColor      color.Color
+	HighlightColor color.Color
+	CheckColor     color.Color
+	// Shapes of the pieces.  [DefaultPieces] if nil.
+	Pieces *PieceSet
+	// Move to highlight.  Zero means no highlight.
+	LastMove chego.Move
+	// Highlight the king of the active player if it is in check.
+	Check bool
+	// Annotations drawn over the pieces.
+	Arrows  []Arrow
+	Circles []Circle
+}
+
+// Arrow is an annotation arrow between the centers of two squares.
+type Arrow struct {
+	From, To chego.Square
+	Color    color.Color
+}
+
+// Circle is an annotation circle around the square.
+type Circle struct {
+	Square chego.Square
+	Color  color.Color
+}
+
+var (
+	defaultLight     = color.NRGBA{0xf0, 0xd9, 0xb5, 0xff}
+	defaultDark      = color.NRGBA{0xb5, 0x88, 0x63, 0xff}
+	defaultHighlight = color.NRGBA{0x9b, 0xc7, 0x00, 0x69}
+	defaultCheck     = color.NRGBA{0xff, 0x00, 0x00, 0xaa}
+	white            = color.NRGBA{0xff, 0xff, 0xff, 0xff}
+	black            = color.NRGBA{0x00, 0x00, 0x00, 0xff}
+)
+
+/*
+element is a single shape of the scene.  The shapes are drawn in the scene
+units, where the board occupies the 360x360 square.
+*/
+type element struct {
+	Shape
+	fill        color.NRGBA
+	stroke      color.NRGBA
+	strokeWidth float64
+	// Text drawn with the baseline starting at the center of the shape.
+	text     string
+	textSize float64
+}
+
+// size returns the size of the image in pixels.
+func (o Options) size() int {
+	if o.Size <= 0 {
+		return boardSize
+	}
+	return o.Size
+}
+
+// nrgba converts the color to NRGBA or returns the default color if c is nil.
+func nrgba(c, def color.Color) color.NRGBA {
+	if c == nil {
+		c = def
+	}
+	return color.NRGBAModel.Convert(c).(color.NRGBA)
+}
+
+// squareOrigin returns the top left corner of the square in the scene units.
+func (o Options) squareOrigin(s chego.Square) Point {
+	x, y := s.File(), 7-s.Rank()
+	if o.Flipped {
+		x, y = 7-x, 7-y
+	}
+	return Point{float64(x * squareSize), float64(y * squareSize)}
+}
+
+// squareCenter returns the center of the square in the scene units.
+func (o Options) squareCenter(s chego.Square) Point {
+	p := o.squareOrigin(s)
+	return Point{p.X + squareSize/2.0, p.Y + squareSize/2.0}
+}
+
+// squareShape returns the polygon covering the square.
+func (o Options) squareShape(s chego.Square) Shape {
+	p := o.squareOrigin(s)
+	return polygon(p.X, p.Y, p.X+squareSize, p.Y, p.X+squareSize,
+		p.Y+squareSize, p.X, p.Y+squareSize)
+}
+
+// scene composes the board image from the shapes in the drawing order.
+func scene(p chego.Position, o Options) []element {
+	light := nrgba(o.LightColor, defaultLight)
+	dark := nrgba(o.DarkColor, defaultDark)
+	pieces := &DefaultPieces
+	if o.Pieces != nil {
+		pieces = o.Pieces
+	}
+
+	elements := make([]element, 0, 160)
+
+	// Squares.
+	for s := range chego.Square(64) {
+		fill := light
+		if (s.File()+s.Rank())%2 == 0 {
+			fill = dark
+		}
+		elements = append(elements, element{Shape: o.squareShape(s), fill: fill})
+	}
+
+	// Last move highlight.
+	if o.LastMove != 0 {
+		highlight := nrgba(o.HighlightColor, defaultHighlight)
+		squares := []int{o.LastMove.From(), o.LastMove.To()}
+		if o.LastMove.IsDrop() {
+			squares = squares[1:]
+		}
+		for _, s := range squares {
+			elements = append(elements, element{
+				Shape: o.squareShape(chego.Square(s)),
+				fill:  highlight,
+			})
+		}
+	}
+
+	// Check highlight.
+	king := p.Bitboards[chego.PieceWKing+p.ActiveColor]
+	if o.Check && king != 0 &&
+		chego.GenChecksCounter(p.Bitboards, 1^p.ActiveColor) > 0 {
+		center := o.squareCenter(chego.Bitboard(king).LSB())
+		elements = append(elements, element{
+			Shape: circle(center.X, center.Y, squareSize/2.0),
+			fill:  nrgba(o.CheckColor, defaultCheck),
+		})
+	}
+
+	// Coordinates are drawn in the corners of the edge squares with the color
+	// of the opposite squares.
+	if o.Coordinates {
+		for i := range 8 {
+			file := chego.NewSquare(i, 0)
+			rank := chego.NewSquare(0, i)
+			if o.Flipped {
+				file, rank = chego.NewSquare(i, 7), chego.NewSquare(7, i)
+			}
+
+			origin := o.squareOrigin(file)
+			elements = append(elements, element{
+				Shape:    Shape{Center: Point{origin.X + 38, origin.Y + 43}},
+				fill:     pick((file.File()+file.Rank())%2 == 0, light, dark),
+				text:     string(rune('a' + i)),
+				textSize: 9,
+			})
+
+			origin = o.squareOrigin(rank)
+			elements = append(elements, element{
+				Shape:    Shape{Center: Point{origin.X + 2, origin.Y + 9}},
+				fill:     pick((rank.File()+rank.Rank())%2 == 0, light, dark),
+				text:     string(rune('1' + i)),
+				textSize: 9,
+			})
+		}
+	}
+
+	// Pieces.
+	for s, piece := range p.Pieces() {
+		fill := pick(piece%2 == chego.ColorWhite, white, black)
+		origin := o.squareOrigin(s)
+		for _, shape := range pieces[piece/2] {
+			elements = append(elements, element{
+				Shape:       translate(shape, origin),
+				fill:        fill,
+				stroke:      black,
+				strokeWidth: 1.5,
+			})
+		}
+	}
+
+	for _, c := range o.Circles {
+		center := o.squareCenter(c.Square)
+		elements = append(elements, element{
+			Shape:       circle(center.X, center.Y, squareSize/2.0-2),
+			stroke:      nrgba(c.Color, defaultHighlight),
+			strokeWidth: 3,
+		})
+	}
+
+	for _, a := range o.Arrows {
+		elements = append(elements, element{
+			Shape: arrow(o.squareCenter(a.From), o.squareCenter(a.To)),
+			fill:  nrgba(a.Color, defaultHighlight),
+		})
+	}
+
+	return elements
+}
+
+// translate returns the shape moved by the specified offset.
+func translate(s Shape, offset Point) Shape {
+	moved := Shape{
+		Points: make([]Point, len(s.Points)),
+		Center: Point{s.Center.X + offset.X, s.Center.Y + offset.Y},
+		Radius: s.Radius,
+	}
+	for i, p := range s.Points {
+		moved.Points[i] = Point{p.X + offset.X, p.Y + offset.Y}
+	}
+	return moved
+}
+
+/*
+arrow returns the polygon of the arrow pointing from one point to another.  The
+arrow starts at the distance from the origin to leave the piece visible.
+*/
+func arrow(from, to Point) Shape {
+	const shaft, headWidth, headLength, start = 4.5, 11.0, 18.0, 10.0
+
+	dx, dy := to.X-from.X, to.Y-from.Y
+	length := math.Hypot(dx, dy)
+	if length == 0 {
+		return Shape{}
+	}
+	// Unit vectors along and across the arrow.
+	ux, uy := dx/length, dy/length
+	nx, ny := -uy, ux
+
+	at := func(along, across float64) Point {
+		return Point{from.X + ux*along + nx*across, from.Y + uy*along + ny*across}
+	}
+	neck := length - headLength
+	return Shape{Points: []Point{
+		at(start, -shaft), at(neck, -shaft), at(neck, -headWidth),
+		at(length, 0),
+		at(neck, headWidth), at(neck, shaft), at(start, shaft),
+	}}
+}
+
+// pick returns a if cond is true and b otherwise.
+func pick(cond bool, a, b color.NRGBA) color.NRGBA {
+	if cond {
+		return a
+	}
+	return b
+}
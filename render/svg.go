@@ -0,0 +1,94 @@
+// svg.go implements the SVG backend of the board renderer.
+
+package render
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/BelikovArtem/chego"
+)
+
+/*
+SVG renders the position as a standalone SVG document.  The document is drawn
+in the 360x360 view box and scaled to the image size specified in the options.
+*/
+func SVG(p chego.Position, opts Options) string {
+	var b strings.Builder
+
+	size := opts.size()
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" `+
+		`height="%d" viewBox="0 0 %d %d">`, size, size, boardSize, boardSize)
+	b.WriteByte('\n')
+
+	for _, e := range scene(p, opts) {
+		writeSVGElement(&b, e)
+	}
+
+	b.WriteString("</svg>\n")
+	return b.String()
+}
+
+// writeSVGElement writes the scene element as a single SVG element.
+func writeSVGElement(b *strings.Builder, e element) {
+	switch {
+	case e.text != "":
+		fmt.Fprintf(b, `<text x="%s" y="%s" font-family="sans-serif" `+
+			`font-size="%s" font-weight="bold"`, num(e.Center.X),
+			num(e.Center.Y), num(e.textSize))
+		writeSVGPaint(b, e)
+		fmt.Fprintf(b, ">%s</text>\n", e.text)
+
+	case len(e.Points) > 0:
+		b.WriteString(`<polygon points="`)
+		for i, p := range e.Points {
+			if i > 0 {
+				b.WriteByte(' ')
+			}
+			b.WriteString(num(p.X) + "," + num(p.Y))
+		}
+		b.WriteByte('"')
+		writeSVGPaint(b, e)
+		b.WriteString("/>\n")
+
+	case e.Radius > 0:
+		fmt.Fprintf(b, `<circle cx="%s" cy="%s" r="%s"`, num(e.Center.X),
+			num(e.Center.Y), num(e.Radius))
+		writeSVGPaint(b, e)
+		b.WriteString("/>\n")
+	}
+}
+
+// writeSVGPaint writes the fill and stroke attributes of the element.
+func writeSVGPaint(b *strings.Builder, e element) {
+	if e.fill.A == 0 {
+		b.WriteString(` fill="none"`)
+	} else {
+		writeSVGColor(b, "fill", e.fill)
+	}
+
+	if e.strokeWidth > 0 && e.stroke.A > 0 {
+		writeSVGColor(b, "stroke", e.stroke)
+		fmt.Fprintf(b, ` stroke-width="%s" stroke-linejoin="round"`,
+			num(e.strokeWidth))
+	}
+}
+
+/*
+writeSVGColor writes the color attribute in the #rrggbb form followed by the
+opacity attribute if the color is translucent.
+*/
+func writeSVGColor(b *strings.Builder, attr string, c color.NRGBA) {
+	fmt.Fprintf(b, ` %s="#%02x%02x%02x"`, attr, c.R, c.G, c.B)
+	if c.A < 0xff {
+		fmt.Fprintf(b, ` %s-opacity="%s"`, attr, num(float64(c.A)/0xff))
+	}
+}
+
+// num formats the coordinate with at most two decimal places.
+func num(f float64) string {
+	return strconv.FormatFloat(math.Round(f*100)/100, 'f', -1, 64)
+}
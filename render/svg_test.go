@@ -0,0 +1,130 @@
+package render
+
+import (
+	"image/color"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/BelikovArtem/chego"
+)
+
+func TestMain(m *testing.M) {
+	chego.InitAttackTables()
+	os.Exit(m.Run())
+}
+
+func TestSVG(t *testing.T) {
+	start := chego.ParseFEN(chego.InitialPos)
+	// Fool's mate.
+	mate := chego.ParseFEN("rnb1kbnr/pppp1ppp/8/4p3/6Pq/5P2/PPPPP2P/RNBQKBNR w KQkq - 1 3")
+	red := color.NRGBA{0xff, 0, 0, 0xff}
+
+	testcases := []struct {
+		name     string
+		p        chego.Position
+		opts     Options
+		contains []string
+		excludes []string
+	}{
+		{
+			"default",
+			start,
+			Options{},
+			[]string{`width="360" height="360" viewBox="0 0 360 360"`,
+				`fill="#f0d9b5"`, `fill="#b58863"`},
+			[]string{"<text", `fill-opacity`},
+		},
+		{
+			"size and colors",
+			start,
+			Options{Size: 720, LightColor: color.White, DarkColor: color.Black},
+			[]string{`width="720" height="720"`, `fill="#ffffff"`},
+			[]string{`fill="#b58863"`},
+		},
+		{
+			"coordinates",
+			start,
+			Options{Coordinates: true},
+			[]string{`>a</text>`, `>h</text>`, `>1</text>`, `>8</text>`},
+			nil,
+		},
+		{
+			"last move",
+			start,
+			Options{LastMove: chego.NewMove(chego.SE4, chego.SE2, chego.MoveNormal)},
+			// The e2 and e4 squares.
+			[]string{`<polygon points="180,270 225,270 225,315 180,315" ` +
+				`fill="#9bc700" fill-opacity="0.41"/>`,
+				`<polygon points="180,180 225,180 225,225 180,225" ` +
+					`fill="#9bc700" fill-opacity="0.41"/>`},
+			nil,
+		},
+		{
+			"check",
+			mate,
+			Options{Check: true},
+			// The e1 square.
+			[]string{`<circle cx="202.5" cy="337.5" r="22.5" fill="#ff0000"`},
+			nil,
+		},
+		{
+			"check disabled",
+			mate,
+			Options{},
+			nil,
+			[]string{`fill="#ff0000"`},
+		},
+		{
+			"no check",
+			start,
+			Options{Check: true},
+			nil,
+			[]string{`fill="#ff0000"`},
+		},
+		{
+			"flipped",
+			start,
+			Options{Flipped: true, Circles: []Circle{{chego.SA1, red}}},
+			// The a1 square is in the top right corner.
+			[]string{`<circle cx="337.5" cy="22.5" r="20.5" fill="none" ` +
+				`stroke="#ff0000"`},
+			nil,
+		},
+		{
+			"arrow",
+			start,
+			Options{Arrows: []Arrow{{chego.SE2, chego.SE4, red}}},
+			// The arrow points from e2 up to the center of e4.
+			[]string{`202.5,202.5 `},
+			nil,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := SVG(tc.p, tc.opts)
+			if !strings.HasPrefix(got, "<svg ") || !strings.HasSuffix(got, "</svg>\n") {
+				t.Fatalf("malformed document: %s", got)
+			}
+			for _, s := range tc.contains {
+				if !strings.Contains(got, s) {
+					t.Fatalf("expected %q in %s", s, got)
+				}
+			}
+			for _, s := range tc.excludes {
+				if strings.Contains(got, s) {
+					t.Fatalf("unexpected %q in %s", s, got)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkSVG(b *testing.B) {
+	p := chego.ParseFEN(chego.InitialPos)
+	opts := Options{Coordinates: true, Check: true}
+	for b.Loop() {
+		SVG(p, opts)
+	}
+}
@@ -0,0 +1,109 @@
+// san.go implements Standard Algebraic Notation.
+
+package chego
+
+import "strings"
+
+// sanPieceSymbols maps each piece type to its SAN symbol.
+var sanPieceSymbols = [12]byte{
+	'P', 'P', 'N', 'N', 'B', 'B', 'R', 'R', 'Q', 'Q', 'K', 'K',
+}
+
+/*
+Move2SAN converts the legal move in the specified position into a Standard
+Algebraic Notation string.
+
+Examples: e4, Nf3, exd5, Rad1, N5xe4, O-O, e8=Q+, Qxf7#.
+Crazyhouse drop moves are written as in UCI, e.g. N@f3, and Antichess
+promotions to the king as e8=K.
+
+The moves are generated and performed by the specified variant, or by
+[Standard] if none is specified.
+*/
+func Move2SAN(p Position, m Move, variant ...Variant) string {
+	v := optionalVariant(variant)
+
+	var b strings.Builder
+	b.Grow(7)
+
+	if m.IsDrop() {
+		b.WriteString(Move2UCI(m))
+	} else if m.Type() == MoveCastling {
+		kingTo, _, _ := p.castlingSquares(m)
+		b.WriteString("O-O")
+		if kingTo%8 == 2 {
+			b.WriteString("-O")
+		}
+	} else {
+		writeSANMove(&b, v, p, m)
+	}
+
+	v.MakeMove(&p, m)
+
+	l := MoveList{}
+	v.GenLegalMoves(p, &l)
+	if genActiveCheckers(p) != 0 {
+		if l.Len() == 0 {
+			b.WriteByte('#')
+		} else {
+			b.WriteByte('+')
+		}
+	}
+
+	return b.String()
+}
+
+/*
+writeSANMove writes the piece symbol, disambiguation, capture mark, destination
+square and promotion piece of the non-castling board move.
+*/
+func writeSANMove(b *strings.Builder, v Variant, p Position, m Move) {
+	from, to := m.From(), m.To()
+	piece := p.GetPieceFromSquare(1 << from)
+	isCapture := p.Bitboards[14]&(1<<to) != 0 || m.Type() == MoveEnPassant
+
+	if piece <= PieceBPawn {
+		if isCapture {
+			b.WriteByte(Square2String[from][0])
+			b.WriteByte('x')
+		}
+		b.WriteString(Square2String[to])
+
+		if m.Type() == MovePromotion {
+			b.WriteByte('=')
+			b.WriteByte(sanPieceSymbols[PieceWKnight+2*m.PromoPiece()])
+		}
+		return
+	}
+
+	b.WriteByte(sanPieceSymbols[piece])
+
+	// Find other pieces of the same type which can move to the same square.
+	l := MoveList{}
+	v.GenLegalMoves(p, &l)
+	ambiguous, sameFile, sameRank := false, false, false
+	for other := range l.All() {
+		if other.To() != to || other.From() == from || other.IsDrop() ||
+			p.GetPieceFromSquare(1<<other.From()) != piece {
+			continue
+		}
+		ambiguous = true
+		sameFile = sameFile || other.From()%8 == from%8
+		sameRank = sameRank || other.From()/8 == from/8
+	}
+
+	if ambiguous {
+		if !sameFile {
+			b.WriteByte(Square2String[from][0])
+		} else if !sameRank {
+			b.WriteByte(Square2String[from][1])
+		} else {
+			b.WriteString(Square2String[from])
+		}
+	}
+
+	if isCapture {
+		b.WriteByte('x')
+	}
+	b.WriteString(Square2String[to])
+}
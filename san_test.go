@@ -0,0 +1,98 @@
+package chego
+
+import (
+	"strings"
+	"testing"
+)
+
+// operaGame is the Opera Game, Paris 1858, in UCI notation.
+var operaGame = []string{
+	"e2e4", "e7e5", "g1f3", "d7d6", "d2d4", "c8g4", "d4e5", "g4f3", "d1f3",
+	"d6e5", "f1c4", "g8f6", "f3b3", "d8e7", "b1c3", "c7c6", "c1g5", "b7b5",
+	"c3b5", "c6b5", "c4b5", "b8d7", "e1c1", "a8d8", "d1d7", "d8d7", "h1d1",
+	"e7e6", "b5d7", "f6d7", "b3b8", "d7b8", "d1d8",
+}
+
+// playUCI plays the moves in UCI notation in the game.
+func playUCI(t testing.TB, g *Game, moves []string) {
+	for _, uci := range moves {
+		m, err := UCI2Move(g.Position, uci)
+		if err != nil {
+			t.Fatal(err)
+		}
+		g.PushMove(m)
+	}
+}
+
+func TestMove2SANGame(t *testing.T) {
+	expected := "e4 e5 Nf3 d6 d4 Bg4 dxe5 Bxf3 Qxf3 dxe5 Bc4 Nf6 Qb3 Qe7 Nc3 c6 " +
+		"Bg5 b5 Nxb5 cxb5 Bxb5+ Nbd7 O-O-O Rd8 Rxd7 Rxd7 Rd1 Qe6 Bxd7+ Nxd7 " +
+		"Qb8+ Nxb8 Rd8#"
+
+	g := NewGame()
+	san := make([]string, 0, len(operaGame))
+	for _, uci := range operaGame {
+		m, err := UCI2Move(g.Position, uci)
+		if err != nil {
+			t.Fatal(err)
+		}
+		san = append(san, Move2SAN(g.Position, m))
+		g.PushMove(m)
+	}
+
+	if got := strings.Join(san, " "); got != expected {
+		t.Fatalf("expected %s got %s", expected, got)
+	}
+}
+
+func TestMove2SAN(t *testing.T) {
+	testcases := []struct {
+		name     string
+		fen      string
+		move     Move
+		expected string
+	}{
+		{
+			"promotion",
+			"4k3/1P6/8/8/8/8/8/4K3 w - - 0 1",
+			NewPromotionMove(SB8, SB7, PromotionQueen),
+			"b8=Q+",
+		},
+		{
+			"en passant",
+			"4k3/8/8/3Pp3/8/8/8/4K3 w - e6 0 1",
+			NewMove(SE6, SD5, MoveEnPassant),
+			"dxe6",
+		},
+		{
+			"rank disambiguation",
+			"4k3/8/8/8/R7/8/8/R3K3 w - - 0 1",
+			NewMove(SA3, SA1, MoveNormal),
+			"R1a3",
+		},
+		{
+			"square disambiguation",
+			"4k3/8/8/8/Q1Q5/8/Q7/4K3 w - - 0 1",
+			NewMove(SB3, SA4, MoveNormal),
+			"Qa4b3",
+		},
+		{
+			"short castling",
+			"4k3/8/8/8/8/8/8/4K2R w K - 0 1",
+			NewMove(SG1, SE1, MoveCastling),
+			"O-O",
+		},
+		{
+			"drop",
+			"4k3/8/8/8/8/8/8/4K3[N] w - - 0 1",
+			NewDropMove(SF6, PieceWKnight),
+			"N@f6+",
+		},
+	}
+
+	for _, tc := range testcases {
+		if got := Move2SAN(ParseFEN(tc.fen), tc.move); got != tc.expected {
+			t.Fatalf("%s: expected %s got %s", tc.name, tc.expected, got)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+// order.go implements the move ordering heuristics.
+
+package search
+
+import "github.com/BelikovArtem/chego"
+
+// Ordering scores of the move categories: the move from the transposition
+// table is searched first, then the captures, promotions and killer moves.
+const (
+	scoreTTMove    = 1 << 30
+	scoreCapture   = 1 << 24
+	scorePromotion = 1 << 23
+	scoreKiller    = 1 << 22
+)
+
+// Values of the pieces used in the MVV-LVA ordering indexed by the piece type
+// divided by two.
+var orderValue = [6]int{1, 3, 3, 5, 9, 20}
+
+// isCapture reports whether the move captures a piece.
+func isCapture(p *chego.Position, m chego.Move) bool {
+	if m.IsDrop() {
+		return false
+	}
+	enemy := p.Bitboards[12+(1^p.ActiveColor)]
+	return enemy&(1<<m.To()) != 0 || m.Type() == chego.MoveEnPassant
+}
+
+// isPromotion reports whether the move promotes a pawn.
+func isPromotion(m chego.Move) bool {
+	return !m.IsDrop() && m.Type() == chego.MovePromotion
+}
+
+/*
+scoreMoves assigns the ordering scores to the moves.  The captures are ordered
+by the Most Valuable Victim - Least Valuable Attacker heuristic, the quiet
+moves by the killer and history heuristics.
+*/
+func (s *Searcher) scoreMoves(p *chego.Position, moves []chego.Move,
+	scores []int, ply int, ttMove chego.Move) {
+	for i, m := range moves {
+		switch {
+		case m == ttMove:
+			scores[i] = scoreTTMove
+
+		case isCapture(p, m):
+			victim := chego.PieceWPawn
+			if m.Type() != chego.MoveEnPassant {
+				victim = p.GetPieceFromSquare(1 << m.To())
+			}
+			attacker := p.GetPieceFromSquare(1 << m.From())
+			scores[i] = scoreCapture + 10*orderValue[victim/2] -
+				orderValue[attacker/2]
+
+		case isPromotion(m):
+			scores[i] = scorePromotion + m.PromoPiece()
+
+		case m == s.killers[ply][0]:
+			scores[i] = scoreKiller + 1
+
+		case m == s.killers[ply][1]:
+			scores[i] = scoreKiller
+
+		default:
+			scores[i] = s.history[m.From()][m.To()]
+		}
+	}
+}
+
+/*
+legalMoves returns the moves stored in the list as a single slice.  The board
+moves are sliced in place, and copied along with the drops only in Crazyhouse.
+*/
+func legalMoves(l *chego.MoveList) []chego.Move {
+	moves := l.Moves[:l.LastMoveIndex]
+	if len(l.Drops) > 0 {
+		moves = append(moves[:len(moves):len(moves)], l.Drops...)
+	}
+	return moves
+}
+
+/*
+moveScores returns the slice of n ordering scores.  The buffer is used unless
+there are more moves than it can hold, e.g. in Crazyhouse.
+*/
+func moveScores(buf *[256]int, n int) []int {
+	if n <= len(buf) {
+		return buf[:n]
+	}
+	return make([]int, n)
+}
+
+/*
+pickMove moves the best scored move among the remaining ones to the i-th place
+and returns it.  The selection is cheaper than sorting, since most of the
+moves are never searched after the beta cutoff.
+*/
+func pickMove(moves []chego.Move, scores []int, i int) chego.Move {
+	best := i
+	for j := i + 1; j < len(moves); j++ {
+		if scores[j] > scores[best] {
+			best = j
+		}
+	}
+	moves[i], moves[best] = moves[best], moves[i]
+	scores[i], scores[best] = scores[best], scores[i]
+	return moves[i]
+}
+
+// addKiller stores the quiet move which caused the beta cutoff.
+func (s *Searcher) addKiller(m chego.Move, ply int) {
+	if s.killers[ply][0] != m {
+		s.killers[ply][1] = s.killers[ply][0]
+		s.killers[ply][0] = m
+	}
+}
+
+/*
+addHistory rewards the quiet move which caused the beta cutoff.  The scores are
+halved once they grow too large, so the recent cutoffs weigh more.
+*/
+func (s *Searcher) addHistory(m chego.Move, depth int) {
+	h := &s.history[m.From()][m.To()]
+	*h += depth * depth
+	if *h >= scoreKiller {
+		for from := range s.history {
+			for to := range s.history[from] {
+				s.history[from][to] /= 2
+			}
+		}
+	}
+}
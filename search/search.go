@@ -0,0 +1,546 @@
+/*
+Package search implements the chess engine built on top of the chego move
+generator: negamax alpha-beta search with iterative deepening, aspiration
+windows and quiescence search.  The moves are ordered by the transposition
+table, MVV-LVA, killer and history heuristics, and the tree is pruned with the
+null-move pruning and late move reductions.
+
+The search plays by the standard chess rules, including the Chess960
+castling, and scores the terminal positions by them: checkmate, stalemate,
+fifty-move rule and repetitions.  The positions of the other variants are
+rejected, since their moves and game ends are not handled by the search.
+
+Make sure to call [chego.InitAttackTables] and [chego.InitZobristKeys] ONCE
+before searching.
+*/
+package search
+
+import (
+	"context"
+	"time"
+
+	"github.com/BelikovArtem/chego"
+	"github.com/BelikovArtem/chego/eval"
+)
+
+const (
+	// MaxPly is the maximal depth of the searched lines.
+	MaxPly = 128
+	// Infinity is greater than any score.
+	Infinity = 32000
+	// MateScore is the score of the checkmate at the root.  The checkmate
+	// in n plies is scored as MateScore-n.
+	MateScore = 31000
+)
+
+// nullMoveReduction is the depth reduction of the null-move search.
+const nullMoveReduction = 2
+
+// aspirationWindow is the initial half-width of the aspiration window.
+const aspirationWindow = 50
+
+// checkInterval is the number of nodes between the time and context checks.
+const checkInterval = 2048
+
+/*
+Limits bounds the search.  Zero values mean no limit.  The search without
+limits stops only when its context is cancelled or [MaxPly] is reached.
+*/
+type Limits struct {
+	// Maximal depth in plies.
+	Depth int
+	// Maximal number of searched nodes.
+	Nodes int
+	// Maximal search time.
+	Time time.Duration
+}
+
+// Info reports the result of a single iteration of the iterative deepening.
+type Info struct {
+	Depth int
+	// Score from the active player's side in centipawns, see [IsMate].
+	Score int
+	Nodes int
+	Time  time.Duration
+	// Principal variation: the best line found.
+	PV []chego.Move
+}
+
+// Result is the outcome of the search.
+type Result struct {
+	// Best move found.  Zero if there are no legal moves.
+	Move chego.Move
+	// Score of the best move from the active player's side.
+	Score int
+	// Depth of the last completed iteration.
+	Depth int
+	Nodes int
+	PV    []chego.Move
+}
+
+/*
+IsMate reports whether the score means a forced checkmate.  The positive
+scores mean the active player mates.
+*/
+func IsMate(score int) bool {
+	return score >= MateScore-MaxPly || score <= -MateScore+MaxPly
+}
+
+/*
+MateIn returns the number of moves until the checkmate for the mate score:
+positive if the active player mates and negative if it gets mated.
+*/
+func MateIn(score int) int {
+	if score > 0 {
+		return (MateScore - score + 1) / 2
+	}
+	return -(MateScore + score) / 2
+}
+
+/*
+Searcher searches the best moves.  It keeps the transposition table and the
+move ordering statistics between the searches, so reuse it for the positions
+of the same game.  The Searcher is not safe for concurrent use.
+*/
+type Searcher struct {
+	// Evaluate scores the position from the active player's side.
+	// [eval.Evaluate] by default.
+	Evaluate func(chego.Position) int
+	// OnInfo is called after each completed iteration, if not nil.
+	OnInfo func(Info)
+	// Zobrist keys of the positions played before the searched one, from
+	// the oldest to the latest.  Used to detect the repetitions.
+	History []uint64
+
+	tt      *table
+	killers [MaxPly][2]chego.Move
+	history [64][64]int
+	// Zobrist keys of the game history and the searched line.
+	keys []uint64
+	// Triangular principal variation table.
+	pv    [MaxPly][MaxPly]chego.Move
+	pvLen [MaxPly]int
+
+	ctx      context.Context
+	limits   Limits
+	deadline time.Time
+	nodes    int
+	stopped  bool
+	rootBest chego.Move
+}
+
+// New creates a new searcher with the transposition table of the specified
+// size in megabytes.
+func New(hashMB int) *Searcher {
+	return &Searcher{tt: newTable(hashMB)}
+}
+
+// Clear forgets the results of the previous searches, e.g. before a new game.
+func (s *Searcher) Clear() {
+	s.tt.clear()
+	s.killers = [MaxPly][2]chego.Move{}
+	s.history = [64][64]int{}
+}
+
+/*
+Search searches the best move in the position within the limits.  The search
+stops early if the context is cancelled, and returns the result of the last
+completed iteration.
+
+Returns the zero Result if the position isn't played by the standard rules.
+*/
+func (s *Searcher) Search(ctx context.Context, p chego.Position,
+	limits Limits) Result {
+	if p.Variant != chego.VariantStandard {
+		return Result{}
+	}
+
+	start := time.Now()
+
+	s.ctx = ctx
+	s.limits = limits
+	s.deadline = time.Time{}
+	if limits.Time > 0 {
+		s.deadline = start.Add(limits.Time)
+	}
+	s.nodes = 0
+	s.stopped = false
+	s.rootBest = 0
+	s.killers = [MaxPly][2]chego.Move{}
+	s.keys = append(s.keys[:0], s.History...)
+
+	maxDepth := MaxPly - 1
+	if limits.Depth > 0 {
+		maxDepth = min(limits.Depth, maxDepth)
+	}
+
+	var res Result
+	for depth := 1; depth <= maxDepth; depth++ {
+		score := s.aspiration(&p, depth, res.Score)
+		if s.stopped {
+			break
+		}
+
+		res = Result{
+			Move:  s.pv[0][0],
+			Score: score,
+			Depth: depth,
+			PV:    append([]chego.Move(nil), s.pv[0][:s.pvLen[0]]...),
+		}
+		if s.pvLen[0] == 0 {
+			res.Move = s.rootBest
+		}
+
+		if s.OnInfo != nil {
+			s.OnInfo(Info{Depth: depth, Score: score, Nodes: s.nodes,
+				Time: time.Since(start), PV: res.PV})
+		}
+
+		// The shortest mate is found, no need to search deeper.
+		if IsMate(score) && MateScore-abs(score) <= depth {
+			break
+		}
+	}
+
+	// The first iteration was interrupted.
+	if res.Move == 0 {
+		res.Move = s.rootBest
+		if res.Move == 0 {
+			l := chego.MoveList{}
+			chego.GenLegalMoves(p, &l)
+			for m := range l.All() {
+				res.Move = m
+				break
+			}
+		}
+		if res.Move != 0 {
+			res.PV = []chego.Move{res.Move}
+		}
+	}
+
+	res.Nodes = s.nodes
+	return res
+}
+
+/*
+aspiration searches the root within the narrow window around the score of the
+previous iteration.  The window is widened each time the score falls outside
+of it.
+*/
+func (s *Searcher) aspiration(p *chego.Position, depth, prev int) int {
+	if depth < 4 {
+		return s.negamax(p, depth, 0, -Infinity, Infinity, false)
+	}
+
+	delta := aspirationWindow
+	alpha, beta := max(prev-delta, -Infinity), min(prev+delta, Infinity)
+	for {
+		score := s.negamax(p, depth, 0, alpha, beta, false)
+		switch {
+		case s.stopped:
+			return 0
+		case score <= alpha:
+			alpha = max(score-delta, -Infinity)
+		case score >= beta:
+			beta = min(score+delta, Infinity)
+		default:
+			return score
+		}
+		delta *= 2
+	}
+}
+
+// shouldStop reports whether the search must be stopped due to the limits.
+func (s *Searcher) shouldStop() bool {
+	if s.stopped {
+		return true
+	}
+
+	if s.limits.Nodes > 0 && s.nodes >= s.limits.Nodes {
+		s.stopped = true
+	} else if s.nodes%checkInterval == 0 {
+		s.stopped = s.ctx.Err() != nil ||
+			!s.deadline.IsZero() && time.Now().After(s.deadline)
+	}
+	return s.stopped
+}
+
+// evaluate returns the static evaluation of the position.
+func (s *Searcher) evaluate(p *chego.Position) int {
+	if s.Evaluate != nil {
+		return s.Evaluate(*p)
+	}
+	return eval.Evaluate(*p)
+}
+
+/*
+negamax searches the position to the specified depth and returns its score
+from the active player's side within the [alpha, beta] window.
+*/
+func (s *Searcher) negamax(p *chego.Position, depth, ply, alpha, beta int,
+	nullAllowed bool) int {
+	s.pvLen[ply] = ply
+	if s.shouldStop() {
+		return 0
+	}
+
+	key := chego.ZobristKey(*p)
+	if ply > 0 {
+		if s.isDraw(p, key) {
+			return 0
+		}
+
+		// The shorter mate has been already found.
+		alpha = max(alpha, -MateScore+ply)
+		beta = min(beta, MateScore-ply-1)
+		if alpha >= beta {
+			return alpha
+		}
+	}
+
+	check := inCheck(p)
+	// Check extension: never enter the quiescence search in check.
+	if check {
+		depth++
+	}
+	if depth <= 0 {
+		return s.quiesce(p, ply, alpha, beta)
+	}
+	if ply >= MaxPly-1 {
+		return s.evaluate(p)
+	}
+	s.nodes++
+
+	pvNode := beta-alpha > 1
+	var ttMove chego.Move
+	if e, ok := s.tt.probe(key); ok {
+		ttMove = e.move
+		score := fromTT(int(e.score), ply)
+		if !pvNode && ply > 0 && int(e.depth) >= depth &&
+			(e.bound == boundExact ||
+				e.bound == boundLower && score >= beta ||
+				e.bound == boundUpper && score <= alpha) {
+			return score
+		}
+	}
+
+	// Null-move pruning: if passing the turn still fails high, the position
+	// is good enough to skip the search.  Doesn't work in zugzwang, hence
+	// skipped in the pawn endgames.
+	if nullAllowed && !pvNode && !check && depth >= 3 &&
+		hasPieces(p) && s.evaluate(p) >= beta {
+		null := *p
+		null.ActiveColor ^= 1
+		null.EPTarget = 0
+
+		s.keys = append(s.keys, key)
+		score := -s.negamax(&null, depth-1-nullMoveReduction, ply+1, -beta,
+			-beta+1, false)
+		s.keys = s.keys[:len(s.keys)-1]
+
+		if s.stopped {
+			return 0
+		}
+		if score >= beta {
+			// Don't trust the unproven mates.
+			return min(score, MateScore-MaxPly-1)
+		}
+	}
+
+	l := chego.MoveList{}
+	chego.GenLegalMoves(*p, &l)
+	if l.Len() == 0 {
+		if check {
+			return -MateScore + ply
+		}
+		return 0
+	}
+
+	moves := legalMoves(&l)
+	var buf [256]int
+	scores := moveScores(&buf, len(moves))
+	s.scoreMoves(p, moves, scores, ply, ttMove)
+
+	best, bestMove, bound := -Infinity, chego.Move(0), boundUpper
+	s.keys = append(s.keys, key)
+	for i := range moves {
+		m := pickMove(moves, scores, i)
+		quiet := !isCapture(p, m) && !isPromotion(m)
+
+		child := *p
+		child.MakeMove(m)
+
+		var score int
+		if i == 0 {
+			score = -s.negamax(&child, depth-1, ply+1, -beta, -alpha, true)
+		} else {
+			// Late move reductions: the late quiet moves are unlikely to be
+			// good, so they are searched to the reduced depth first.
+			reduction := 0
+			if depth >= 3 && i >= 3 && quiet && !check && !inCheck(&child) {
+				reduction = 1
+				if i >= 6 {
+					reduction = depth / 3
+				}
+			}
+
+			// Prove that the move is worse than the best one with the null
+			// window, and research it if it's not.
+			score = -s.negamax(&child, depth-1-reduction, ply+1, -alpha-1,
+				-alpha, true)
+			if score > alpha && reduction > 0 {
+				score = -s.negamax(&child, depth-1, ply+1, -alpha-1, -alpha,
+					true)
+			}
+			if score > alpha && score < beta {
+				score = -s.negamax(&child, depth-1, ply+1, -beta, -alpha, true)
+			}
+		}
+
+		if s.stopped {
+			s.keys = s.keys[:len(s.keys)-1]
+			return 0
+		}
+
+		if score <= best {
+			continue
+		}
+		best, bestMove = score, m
+		if score <= alpha {
+			continue
+		}
+
+		alpha = score
+		bound = boundExact
+		s.updatePV(m, ply)
+		if ply == 0 {
+			s.rootBest = m
+		}
+
+		if score >= beta {
+			bound = boundLower
+			if quiet {
+				s.addKiller(m, ply)
+				s.addHistory(m, depth)
+			}
+			break
+		}
+	}
+	s.keys = s.keys[:len(s.keys)-1]
+
+	s.tt.store(key, depth, toTT(best, ply), bound, bestMove)
+	return best
+}
+
+/*
+quiesce searches only the captures and promotions until the position becomes
+quiet, so the static evaluation isn't applied in the middle of the exchange.
+In check, all moves are searched.
+*/
+func (s *Searcher) quiesce(p *chego.Position, ply, alpha, beta int) int {
+	s.pvLen[ply] = ply
+	if s.shouldStop() {
+		return 0
+	}
+	s.nodes++
+
+	check := inCheck(p)
+	if ply >= MaxPly-1 {
+		return s.evaluate(p)
+	}
+
+	// The active player can avoid the captures, if not in check.
+	best := -Infinity
+	if !check {
+		best = s.evaluate(p)
+		if best >= beta {
+			return best
+		}
+		alpha = max(alpha, best)
+	}
+
+	l := chego.MoveList{}
+	chego.GenLegalMoves(*p, &l)
+	if l.Len() == 0 && check {
+		return -MateScore + ply
+	}
+
+	moves := legalMoves(&l)
+	var buf [256]int
+	scores := moveScores(&buf, len(moves))
+	s.scoreMoves(p, moves, scores, ply, 0)
+
+	for i := range moves {
+		m := pickMove(moves, scores, i)
+		// The captures and promotions are ordered first.
+		if !check && scores[i] < scorePromotion {
+			break
+		}
+
+		child := *p
+		child.MakeMove(m)
+		score := -s.quiesce(&child, ply+1, -beta, -alpha)
+		if s.stopped {
+			return 0
+		}
+
+		if score > best {
+			best = score
+			if score > alpha {
+				alpha = score
+				if score >= beta {
+					break
+				}
+			}
+		}
+	}
+
+	return best
+}
+
+// updatePV sets the move followed by the child's line as the line of the ply.
+func (s *Searcher) updatePV(m chego.Move, ply int) {
+	s.pv[ply][ply] = m
+	n := copy(s.pv[ply][ply+1:], s.pv[ply+1][ply+1:s.pvLen[ply+1]])
+	s.pvLen[ply] = ply + 1 + n
+}
+
+/*
+isDraw reports whether the position is drawn by the fifty-move rule or by the
+repetition.  A single repetition is enough, since the players can repeat the
+moves again.
+*/
+func (s *Searcher) isDraw(p *chego.Position, key uint64) bool {
+	if p.HalfmoveCnt >= 100 {
+		return true
+	}
+
+	// Only the positions after the last irreversible move can repeat.
+	for i := len(s.keys) - 2; i >= 0 && i >= len(s.keys)-p.HalfmoveCnt; i -= 2 {
+		if s.keys[i] == key {
+			return true
+		}
+	}
+	return false
+}
+
+// inCheck reports whether the king of the active player is in check.
+func inCheck(p *chego.Position) bool {
+	return p.Bitboards[chego.PieceWKing+p.ActiveColor] != 0 &&
+		chego.GenChecksCounter(p.Bitboards, 1^p.ActiveColor) > 0
+}
+
+// hasPieces reports whether the active player has pieces other than pawns.
+func hasPieces(p *chego.Position) bool {
+	c := p.ActiveColor
+	return p.Bitboards[12+c]&^(p.Bitboards[chego.PieceWPawn+c]|
+		p.Bitboards[chego.PieceWKing+c]) != 0
+}
+
+// abs returns the absolute value of x.
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
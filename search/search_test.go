@@ -0,0 +1,198 @@
+package search
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/BelikovArtem/chego"
+)
+
+func TestMain(m *testing.M) {
+	chego.InitAttackTables()
+	chego.InitZobristKeys()
+	os.Exit(m.Run())
+}
+
+func TestSearchBestMove(t *testing.T) {
+	testcases := []struct {
+		name  string
+		fen   string
+		depth int
+		// Expected best move, or "" if there are several equal moves.
+		move string
+		// Expected mate in moves, or 0 if the score isn't a mate.
+		mate int
+	}{
+		{"back rank mate", "6k1/5ppp/8/8/8/8/5PPP/R5K1 w - - 0 1", 2, "a1a8", 1},
+		{"scholar's mate", "r1bqkbnr/pppp1ppp/2n5/4p3/2B1P3/5Q2/PPPP1PPP/RNB1K1NR w KQkq - 4 4",
+			2, "f3f7", 1},
+		{"queen and knight mate", "6rk/6pp/8/6N1/8/8/8/1Q4K1 w - - 0 1", 3,
+			"b1h7", 1},
+		// 1. Rb7 Kg8 2. Ra8#
+		{"rook ladder", "7k/8/8/8/8/8/R7/1R4K1 w - - 0 1", 4, "", 2},
+		{"hanging queen", "rnb1kbnr/pppp1ppp/8/4p1q1/4P3/3P4/PPP2PPP/RNBQKBNR w KQkq - 0 1",
+			3, "c1g5", 0},
+		// Qf7 is a stalemate.
+		{"mate instead of stalemate", "7k/8/6K1/8/8/8/8/5Q2 w - - 0 1", 3,
+			"f1f8", 1},
+	}
+
+	for _, tc := range testcases {
+		res := New(16).Search(context.Background(), chego.ParseFEN(tc.fen),
+			Limits{Depth: tc.depth})
+
+		if got := chego.Move2UCI(res.Move); tc.move != "" && got != tc.move {
+			t.Fatalf("%s: expected %s got %s (score %d)", tc.name, tc.move,
+				got, res.Score)
+		}
+		if tc.mate != 0 && (!IsMate(res.Score) || MateIn(res.Score) != tc.mate) {
+			t.Fatalf("%s: expected mate in %d got score %d", tc.name, tc.mate,
+				res.Score)
+		}
+		if tc.mate == 0 && IsMate(res.Score) {
+			t.Fatalf("%s: unexpected mate score %d", tc.name, res.Score)
+		}
+		if len(res.PV) == 0 || res.PV[0] != res.Move {
+			t.Fatalf("%s: invalid pv %v", tc.name, res.PV)
+		}
+	}
+}
+
+func TestSearchMated(t *testing.T) {
+	// Black to move is mated in one: 1... Kg8 2. Ra8#.
+	p := chego.ParseFEN("7k/8/6K1/8/8/8/8/R7 b - - 0 1")
+	res := New(1).Search(context.Background(), p, Limits{Depth: 4})
+	if !IsMate(res.Score) || MateIn(res.Score) != -1 {
+		t.Fatalf("expected to be mated in 1 got score %d", res.Score)
+	}
+}
+
+func TestSearchStalemate(t *testing.T) {
+	p := chego.ParseFEN("7k/5Q2/6K1/8/8/8/8/8 b - - 0 1")
+	res := New(1).Search(context.Background(), p, Limits{Depth: 3})
+	if res.Move != 0 || res.Score != 0 {
+		t.Fatalf("expected no move and draw score got %s %d",
+			chego.Move2UCI(res.Move), res.Score)
+	}
+}
+
+func TestSearchVariant(t *testing.T) {
+	// exd5 explodes the black king in Atomic, but not in the standard chess.
+	p := chego.ParseVariantFEN("8/8/4k3/3p4/4P3/8/8/4K3 w - - 0 1",
+		chego.VariantAtomic)
+	res := New(1).Search(context.Background(), p, Limits{Depth: 2})
+	if res.Move != 0 {
+		t.Fatalf("expected no move got %s", chego.Move2UCI(res.Move))
+	}
+}
+
+func TestSearchRepetition(t *testing.T) {
+	// White is a rook down, hence prefers to repeat the position.
+	p := chego.ParseFEN("7k/8/8/8/8/6r1/8/K7 w - - 10 40")
+
+	child := p
+	child.MakeMove(chego.NewMove(chego.SB2, chego.SA1, chego.MoveNormal))
+
+	s := New(1)
+	s.History = []uint64{chego.ZobristKey(child)}
+	res := s.Search(context.Background(), p, Limits{Depth: 3})
+	if chego.Move2UCI(res.Move) != "a1b2" || res.Score != 0 {
+		t.Fatalf("expected the repetition got %s %d",
+			chego.Move2UCI(res.Move), res.Score)
+	}
+
+	// The repetition is impossible after the irreversible move.
+	p.HalfmoveCnt = 0
+	res = s.Search(context.Background(), p, Limits{Depth: 3})
+	if res.Score >= 0 {
+		t.Fatalf("expected the losing score got %d", res.Score)
+	}
+}
+
+func TestSearchLimits(t *testing.T) {
+	p := chego.ParseFEN("r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1")
+
+	var infos []Info
+	s := New(16)
+	s.OnInfo = func(info Info) { infos = append(infos, info) }
+	res := s.Search(context.Background(), p, Limits{Depth: 4})
+	if res.Depth != 4 || len(infos) != 4 || infos[3].Depth != 4 {
+		t.Fatalf("expected depth 4 got %d with %d infos", res.Depth, len(infos))
+	}
+	for i := 1; i < len(infos); i++ {
+		if infos[i].Nodes < infos[i-1].Nodes {
+			t.Fatalf("expected increasing node counts got %+v", infos)
+		}
+	}
+
+	res = New(16).Search(context.Background(), p, Limits{Nodes: 5000})
+	if res.Nodes > 5000 || res.Move == 0 {
+		t.Fatalf("expected at most 5000 nodes got %d", res.Nodes)
+	}
+
+	// Even the tiny limit produces a legal move.
+	res = New(16).Search(context.Background(), p, Limits{Nodes: 1})
+	if res.Move == 0 {
+		t.Fatalf("expected a move")
+	}
+
+	start := time.Now()
+	res = New(16).Search(context.Background(), p,
+		Limits{Time: 100 * time.Millisecond})
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond ||
+		res.Move == 0 {
+		t.Fatalf("expected to stop after 100ms got %v", elapsed)
+	}
+}
+
+func TestSearchCancel(t *testing.T) {
+	p := chego.ParseFEN(chego.InitialPos)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		cancel()
+	}()
+
+	start := time.Now()
+	res := New(16).Search(ctx, p, Limits{})
+	if elapsed := time.Since(start); elapsed > time.Second || res.Move == 0 {
+		t.Fatalf("expected to stop after cancellation got %v", elapsed)
+	}
+}
+
+func TestMateIn(t *testing.T) {
+	testcases := []struct {
+		score    int
+		expected int
+	}{
+		{MateScore - 1, 1},
+		{MateScore - 3, 2},
+		{-MateScore + 2, -1},
+		{-MateScore + 4, -2},
+	}
+
+	for _, tc := range testcases {
+		if !IsMate(tc.score) {
+			t.Fatalf("expected %d to be a mate score", tc.score)
+		}
+		if got := MateIn(tc.score); got != tc.expected {
+			t.Fatalf("%d: expected %d got %d", tc.score, tc.expected, got)
+		}
+	}
+	if IsMate(900) {
+		t.Fatalf("unexpected mate score")
+	}
+}
+
+func BenchmarkSearch(b *testing.B) {
+	p := chego.ParseFEN("r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1")
+	s := New(16)
+	for b.Loop() {
+		s.Clear()
+		res := s.Search(context.Background(), p, Limits{Depth: 5})
+		b.ReportMetric(float64(res.Nodes), "nodes/op")
+	}
+}
@@ -0,0 +1,99 @@
+// tt.go implements the transposition table.
+
+package search
+
+import "github.com/BelikovArtem/chego"
+
+// Bounds of the scores stored in the transposition table.
+const (
+	// The score is exact.
+	boundExact uint8 = iota
+	// The real score is at least the stored one (fail-high).
+	boundLower
+	// The real score is at most the stored one (fail-low).
+	boundUpper
+)
+
+// entry is the result of the search of a single position.
+type entry struct {
+	key   uint64
+	move  chego.Move
+	score int32
+	depth int16
+	bound uint8
+}
+
+/*
+table is the transposition table indexed by the Zobrist keys of the positions.
+It caches the search results of the positions reached via different move
+orders.
+*/
+type table struct {
+	entries []entry
+	mask    uint64
+}
+
+/*
+newTable allocates a table of the specified size in megabytes.  The number of
+entries is rounded down to the power of two.
+*/
+func newTable(megabytes int) *table {
+	n := uint64(max(megabytes, 1)) << 20 / 24
+
+	size := uint64(1)
+	for size*2 <= n {
+		size *= 2
+	}
+
+	return &table{entries: make([]entry, size), mask: size - 1}
+}
+
+// probe returns the stored entry of the position, if there is one.
+func (t *table) probe(key uint64) (entry, bool) {
+	e := t.entries[key&t.mask]
+	return e, e.key == key
+}
+
+/*
+store writes the entry into the table.  The deeper entry of the same position
+is kept, while the entries of other positions are always replaced.
+*/
+func (t *table) store(key uint64, depth, score int, bound uint8,
+	move chego.Move) {
+	e := &t.entries[key&t.mask]
+	if e.key == key && int(e.depth) > depth {
+		return
+	}
+	*e = entry{key: key, move: move, score: int32(score), depth: int16(depth),
+		bound: bound}
+}
+
+// clear removes all entries from the table.
+func (t *table) clear() {
+	clear(t.entries)
+}
+
+/*
+toTT converts the mate score from the distance to the root into the distance
+to the position, since the same position can be reached at different plies.
+*/
+func toTT(score, ply int) int {
+	switch {
+	case score >= MateScore-MaxPly:
+		return score + ply
+	case score <= -MateScore+MaxPly:
+		return score - ply
+	}
+	return score
+}
+
+// fromTT converts the stored mate score back, see [toTT].
+func fromTT(score, ply int) int {
+	switch {
+	case score >= MateScore-MaxPly:
+		return score - ply
+	case score <= -MateScore+MaxPly:
+		return score + ply
+	}
+	return score
+}
@@ -0,0 +1,120 @@
+/*
+snapshot.go implements capturing and restoring the complete state of a live
+game, e.g. to resume the games after the server restart.
+*/
+
+package chego
+
+import "time"
+
+/*
+Snapshot holds everything needed to resume a live game exactly.  It can be
+stored as JSON.
+*/
+type Snapshot struct {
+	// Name of the game variant, see [Variant.Name].
+	Variant  string `json:"variant"`
+	Chess960 bool   `json:"chess960"`
+	StartFEN string `json:"startFen"`
+	// Completed moves in UCI notation.
+	Moves []string `json:"moves"`
+	// Remaining time on the player's clock after each completed move.
+	TimeLeft  []int `json:"timeLeft"`
+	WhiteTime int   `json:"whiteTime"`
+	BlackTime int   `json:"blackTime"`
+	TimeBonus int   `json:"timeBonus"`
+	// Moment the clock of the active player was started.  Zero if the clock
+	// is stopped.
+	TurnStart  time.Time `json:"turnStart"`
+	DrawOffers [2]bool   `json:"drawOffers"`
+	Result     Result    `json:"result"`
+	Winner     Color     `json:"winner"`
+	// Moment the snapshot was taken.
+	Taken time.Time `json:"taken"`
+}
+
+// Snapshot captures the current state of the game.
+func (g *Game) Snapshot() Snapshot {
+	v := g.variant()
+
+	s := Snapshot{
+		Variant:    v.Name(),
+		Chess960:   g.StartPosition.Chess960,
+		StartFEN:   v.SerializeFEN(g.StartPosition),
+		Moves:      make([]string, len(g.MoveStack)),
+		TimeLeft:   make([]int, len(g.MoveStack)),
+		WhiteTime:  g.WhiteTime,
+		BlackTime:  g.BlackTime,
+		TimeBonus:  g.TimeBonus,
+		TurnStart:  g.TurnStart,
+		DrawOffers: g.DrawOffers,
+		Result:     g.Result,
+		Winner:     g.Winner,
+		Taken:      time.Now(),
+	}
+
+	p := g.StartPosition
+	for i, completed := range g.MoveStack {
+		s.Moves[i] = v.Move2UCI(p, completed.Move)
+		s.TimeLeft[i] = completed.TimeLeft
+		v.MakeMove(&p, completed.Move)
+	}
+
+	return s
+}
+
+/*
+RestoreGame restores the game from the snapshot by replaying its moves, which
+also rebuilds the repetition counters.  The game is played by the rules of the
+specified variant, or of the built-in variant with the snapshot variant name if
+no variant is specified.
+
+If the clock was running, it is restarted and the time elapsed since the
+snapshot was taken is charged to the active player, as if the game had never
+been interrupted.
+
+Returns an error if the snapshot variant doesn't match the specified one, or if
+the snapshot contains an invalid FEN string or an illegal move.
+*/
+func RestoreGame(s Snapshot, variant ...Variant) (*Game, error) {
+	var v Variant
+	if len(variant) > 0 {
+		v = variant[0]
+	}
+	v, err := resolveVariant(v, s.Variant)
+	if err != nil {
+		return nil, err
+	}
+
+	g, err := replayGame(v, s.StartFEN, s.Chess960, s.Moves)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range min(len(s.TimeLeft), len(g.MoveStack)) {
+		g.MoveStack[i].TimeLeft = s.TimeLeft[i]
+	}
+	g.WhiteTime = s.WhiteTime
+	g.BlackTime = s.BlackTime
+	g.TimeBonus = s.TimeBonus
+	g.DrawOffers = s.DrawOffers
+	g.Result = s.Result
+	g.Winner = s.Winner
+
+	if !s.TurnStart.IsZero() {
+		g.TurnStart = s.TurnStart
+		g.Clock.Reset(time.Second)
+
+		elapsed := 0
+		if !s.Taken.IsZero() {
+			elapsed = int(time.Since(s.Taken) / time.Second)
+		}
+		if g.Position.ActiveColor == ColorWhite {
+			g.WhiteTime -= elapsed
+		} else {
+			g.BlackTime -= elapsed
+		}
+	}
+
+	return g, nil
+}
@@ -0,0 +1,106 @@
+package chego
+
+import (
+	"encoding/json"
+	"maps"
+	"testing"
+	"time"
+)
+
+func TestSnapshot(t *testing.T) {
+	g := NewGame()
+	g.SetClock(300, 2)
+	defer g.StopClock()
+	// The initial position is repeated twice.
+	playUCI(t, g, []string{"g1f3", "g8f6", "f3g1", "f6g8", "g1f3", "g8f6", "f3g1"})
+	g.MoveStack[0].TimeLeft = 298
+	g.WhiteTime, g.BlackTime = 290, 280
+	g.OfferDraw(ColorWhite)
+
+	data, err := json.Marshal(g.Snapshot())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var s Snapshot
+	if err := json.Unmarshal(data, &s); err != nil {
+		t.Fatal(err)
+	}
+
+	restored, err := RestoreGame(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer restored.StopClock()
+
+	if restored.Position != g.Position {
+		t.Fatalf("expected %s got %s", SerializeFEN(g.Position),
+			SerializeFEN(restored.Position))
+	}
+	if !maps.Equal(restored.Repetitions, g.Repetitions) {
+		t.Fatalf("expected repetitions %v got %v", g.Repetitions,
+			restored.Repetitions)
+	}
+	if len(restored.MoveStack) != len(g.MoveStack) ||
+		restored.MoveStack[0] != g.MoveStack[0] {
+		t.Fatalf("expected moves %v got %v", g.MoveStack, restored.MoveStack)
+	}
+	if restored.WhiteTime != g.WhiteTime || restored.BlackTime != g.BlackTime ||
+		restored.TimeBonus != g.TimeBonus || !restored.TurnStart.Equal(g.TurnStart) {
+		t.Fatalf("clock state is not restored")
+	}
+	if restored.DrawOffers != g.DrawOffers {
+		t.Fatalf("expected draw offers %v got %v", g.DrawOffers,
+			restored.DrawOffers)
+	}
+
+	// The third repetition is detected after the restore.
+	playUCI(t, restored, []string{"f6g8"})
+	if !restored.IsThreefoldRepetition() {
+		t.Fatalf("expected threefold repetition")
+	}
+}
+
+func TestRestoreGameClock(t *testing.T) {
+	g := NewGame()
+	g.SetClock(60, 0)
+	defer g.StopClock()
+	playUCI(t, g, []string{"e2e4"})
+
+	s := g.Snapshot()
+	s.Taken = s.Taken.Add(-5 * time.Second)
+
+	restored, err := RestoreGame(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer restored.StopClock()
+
+	// The downtime is charged to black.
+	if restored.WhiteTime != 60 || restored.BlackTime != 55 {
+		t.Fatalf("expected 60 55 got %d %d", restored.WhiteTime,
+			restored.BlackTime)
+	}
+
+	// Stopped clocks are not charged.
+	g.StopClock()
+	restored, err = RestoreGame(g.Snapshot())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored.BlackTime != 60 || !restored.TurnStart.IsZero() {
+		t.Fatalf("expected stopped clock")
+	}
+}
+
+func TestRestoreGameErrors(t *testing.T) {
+	s := NewGame(Atomic{}).Snapshot()
+	if _, err := RestoreGame(s, Crazyhouse{}); err == nil {
+		t.Fatalf("expected variant mismatch error")
+	}
+
+	s.Moves = []string{"e2e5"}
+	if _, err := RestoreGame(s); err == nil {
+		t.Fatalf("expected illegal move error")
+	}
+}
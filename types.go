@@ -2,15 +2,36 @@
 
 package chego
 
+import "iter"
+
 /*
 Move represents a chess move, encoded as a 16 bit unsigned integer:
   - 0-5:   To (destination) square index.
   - 6-11:  From (origin/source) square index.
   - 12-13: Promotion piece (see [PromotionFlag]).
   - 14-15: Move type (see [MoveType]).
+
+Moves other than promotions always store [PromotionQueen] in the promotion
+bits (see [NewMove]), so the remaining combinations are free to encode the
+variant moves:
+  - The Antichess promotion to the king is stored as the castling type with
+    the knight bits, and is reported by [Move.Type] and [Move.PromoPiece] as
+    the [MovePromotion] to [PromotionKing].
+  - The Crazyhouse drop is stored as the castling type with the bishop bits,
+    and is reported by [Move.Type] as the [MoveDrop].  The origin bits store
+    the dropped piece (see [Move.DropPiece]).
+
+Use these methods instead of reading the bits directly.  The zero Move is
+never a legal move.
 */
 type Move uint16
 
+// Values of the upper four bits of the variant moves.
+const (
+	flagKingPromotion = Move(MoveCastling<<2 | PromotionKnight)
+	flagDrop          = Move(MoveCastling<<2 | PromotionBishop)
+)
+
 // NewMove creates a new move with the promotion piece set to [PromotionQueen].
 func NewMove(to, from, moveType int) Move {
 	return Move(to | (from << 6) | (PromotionQueen << 12) | (moveType << 14))
@@ -19,13 +40,45 @@ func NewMove(to, from, moveType int) Move {
 // NewPromotionMove creates a new move with the promotion type and specified
 // promotion piece.
 func NewPromotionMove(to, from, promoPiece int) Move {
+	if promoPiece == PromotionKing {
+		return Move(to|from<<6) | flagKingPromotion<<12
+	}
 	return Move(to | (from << 6) | (promoPiece << 12) | (MovePromotion << 14))
 }
 
-func (m Move) To() int                   { return int(m & 0x3F) }
-func (m Move) From() int                 { return int(m>>6) & 0x3F }
-func (m Move) PromoPiece() PromotionFlag { return PromotionFlag(m>>12) & 0x3 }
-func (m Move) Type() MoveType            { return MoveType(m>>14) & 0x3 }
+/*
+NewDropMove creates a new Crazyhouse move which drops the piece from the pocket
+onto the specified square.  The color of the piece is ignored.
+*/
+func NewDropMove(to int, piece Piece) Move {
+	return Move(to|piece/2<<6) | flagDrop<<12
+}
+
+func (m Move) To() int   { return int(m & 0x3F) }
+func (m Move) From() int { return int(m>>6) & 0x3F }
+
+func (m Move) PromoPiece() PromotionFlag {
+	if m>>12 == flagKingPromotion {
+		return PromotionKing
+	}
+	return PromotionFlag(m>>12) & 0x3
+}
+
+func (m Move) Type() MoveType {
+	switch m >> 12 {
+	case flagKingPromotion:
+		return MovePromotion
+	case flagDrop:
+		return MoveDrop
+	}
+	return MoveType(m>>14) & 0x3
+}
+
+// IsDrop reports whether the move drops a piece from the pocket.
+func (m Move) IsDrop() bool { return m>>12 == flagDrop }
+
+// DropPiece returns the piece of the specified color dropped by the move.
+func (m Move) DropPiece(c Color) Piece { return 2*m.From() + c }
 
 /*
 MoveList is used to store moves.  The main idea behind it is to preallocate
@@ -39,6 +92,9 @@ type MoveList struct {
 	Moves [218]Move
 	// To keep track of the next move index.
 	LastMoveIndex byte
+	// Crazyhouse drop moves exceed the capacity of Moves by far, so they
+	// are stored separately.  Empty in the other variants.
+	Drops []Move
 }
 
 // Push adds the move to the end of the move list.
@@ -47,6 +103,26 @@ func (l *MoveList) Push(m Move) {
 	l.LastMoveIndex++
 }
 
+// Len returns the number of moves stored in the move list, including drops.
+func (l *MoveList) Len() int { return int(l.LastMoveIndex) + len(l.Drops) }
+
+// All returns an iterator over the moves stored in the move list.  Drops are
+// yielded after the board moves.
+func (l *MoveList) All() iter.Seq[Move] {
+	return func(yield func(Move) bool) {
+		for _, m := range l.Moves[:l.LastMoveIndex] {
+			if !yield(m) {
+				return
+			}
+		}
+		for _, m := range l.Drops {
+			if !yield(m) {
+				return
+			}
+		}
+	}
+}
+
 var (
 	// PieceSymbols maps each piece type to its symbol.
 	PieceSymbols = [12]byte{
@@ -97,6 +173,8 @@ const (
 	PromotionBishop
 	PromotionRook
 	PromotionQueen
+	// Antichess only.  Doesn't fit into the promotion bits, see [Move].
+	PromotionKing
 )
 
 // Color is an allias type to avoid bothersome conversion between int and Color.
@@ -121,6 +199,8 @@ const (
 	MovePromotion
 	// Special pawn move.
 	MoveEnPassant
+	// Crazyhouse drop.  Doesn't fit into the move type bits, see [Move].
+	MoveDrop
 )
 
 /*
@@ -139,6 +219,40 @@ const (
 	CastlingBlackLong  CastlingRights = 8
 )
 
+/*
+VariantKind identifies the built-in rules the position is played under.  It is
+stored in the [Position] to keep the move generation free of the interface
+calls, see [Variant] for the pluggable game rules.
+
+VariantKind is an allias type to avoid bothersome conversion between int and
+VariantKind.
+*/
+type VariantKind = int
+
+const (
+	// Standard chess rules.
+	VariantStandard VariantKind = iota
+	// Captured pieces go to the capturer's pocket and can be dropped back
+	// onto the board instead of making a move.
+	VariantCrazyhouse
+	// The player who checks the enemy king three times wins.
+	VariantThreeCheck
+	// The player whose king reaches one of the central squares wins.
+	VariantKingOfTheHill
+	// Captures explode the neighbouring pieces.  The player who explodes the
+	// enemy king wins.
+	VariantAtomic
+	// Captures are compulsory and the king is an ordinary piece.  The player
+	// who loses all pieces or has no moves wins.
+	VariantAntichess
+	// White has 36 pawns and no king.  White wins by checkmate, black wins by
+	// capturing all white pieces.
+	VariantHorde
+	// Checks are forbidden.  The player whose king reaches the eighth rank
+	// first wins.
+	VariantRacingKings
+)
+
 // Result represents the possible outcomes of a chess game.
 type Result int
 
@@ -152,4 +266,10 @@ const (
 	ResultThreefoldRepetition
 	ResultResignation
 	ResultDrawByAgreement
+	ResultThreeCheck
+	ResultKingOfTheHill
+	ResultKingExploded
+	ResultAntichessWin
+	ResultHordeCaptured
+	ResultRaceFinished
 )
@@ -0,0 +1,55 @@
+package chego
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestMoveListAll(t *testing.T) {
+	l := MoveList{}
+	expected := []Move{
+		NewMove(SE4, SE2, MoveNormal),
+		NewMove(SG1, SE1, MoveCastling),
+		NewPromotionMove(SA8, SA7, PromotionKnight),
+	}
+
+	for _, m := range expected {
+		l.Push(m)
+	}
+
+	if got := slices.Collect(l.All()); !slices.Equal(got, expected) {
+		t.Fatalf("expected %v got %v", expected, got)
+	}
+}
+
+func TestNewDropMove(t *testing.T) {
+	for piece := PieceWPawn; piece <= PieceBQueen; piece++ {
+		m := NewDropMove(SE4, piece)
+		if !m.IsDrop() || m.Type() != MoveDrop || m.To() != SE4 {
+			t.Fatalf("piece %d: invalid drop move %016b", piece, m)
+		}
+		if got := m.DropPiece(piece % 2); got != piece {
+			t.Fatalf("expected piece %d got %d", piece, got)
+		}
+	}
+
+	// The zero move is used as the "no move" sentinel.
+	if Move(0).IsDrop() || NewMove(SE4, SE2, MoveNormal).IsDrop() {
+		t.Fatalf("unexpected drop move")
+	}
+}
+
+func TestNewPromotionMove(t *testing.T) {
+	for promo := PromotionKnight; promo <= PromotionKing; promo++ {
+		m := NewPromotionMove(SE8, SE7, promo)
+		if m.To() != SE8 || m.From() != SE7 || m.Type() != MovePromotion ||
+			m.PromoPiece() != promo {
+			t.Fatalf("promotion %d: invalid move %016b", promo, m)
+		}
+	}
+
+	// Castling must not be confused with the king promotion.
+	if m := NewMove(SG1, SE1, MoveCastling); m.Type() != MoveCastling {
+		t.Fatalf("expected castling got type %d", m.Type())
+	}
+}
@@ -1,18 +1,33 @@
-// uci.go implements Universal Chess Interface.
+// uci.go implements the move notation of the Universal Chess Interface.  The
+// protocol itself is spoken by the chego-uci command.
 
 package chego
 
-import "strings"
+import (
+	"fmt"
+	"strings"
+)
 
 /*
 Move2UCI converts the move into a long algebraic notation string.
 
-Examples: e2e4, e7e5, e1g1 (white short castling), e7e8q (for promotion).
+Examples: e2e4, e7e5, e1g1 (white short castling), e7e8q (for promotion),
+e7e8k (for Antichess promotion to the king).
+Chess960 castling moves are written as the king capturing its own rook, e.g.
+e1h1 for the white short castling.  Crazyhouse drop moves are written as the
+uppercase piece symbol followed by '@' and the destination square, e.g. N@f3.
 */
 func Move2UCI(m Move) string {
 	var b strings.Builder
 	b.Grow(4)
 
+	if m.IsDrop() {
+		b.WriteByte(PieceSymbols[m.DropPiece(ColorWhite)])
+		b.WriteByte('@')
+		b.WriteString(Square2String[m.To()])
+		return b.String()
+	}
+
 	b.WriteString(Square2String[m.From()])
 	b.WriteString(Square2String[m.To()])
 
@@ -26,8 +41,53 @@ func Move2UCI(m Move) string {
 			b.WriteByte('r')
 		case PromotionQueen:
 			b.WriteByte('q')
+		case PromotionKing:
+			b.WriteByte('k')
 		}
 	}
 
 	return b.String()
 }
+
+/*
+UCI2Move parses the move in long algebraic notation and returns the matching
+legal move in the specified position.  Castling moves are accepted both in the
+standard (e1g1) and in the Chess960 (e1h1) notation, regardless of the
+position type, unless it is ambiguous with another king move.
+
+Returns an error if the string doesn't describe a legal move.
+*/
+func UCI2Move(p Position, uci string) (Move, error) {
+	l := MoveList{}
+	GenLegalMoves(p, &l)
+	return uci2Move(p, &l, uci)
+}
+
+// uci2Move returns the move from the list of legal moves matching the string.
+func uci2Move(p Position, l *MoveList, uci string) (Move, error) {
+	alt := Move(0)
+	for m := range l.All() {
+		if Move2UCI(m) == uci {
+			return m, nil
+		}
+
+		if m.Type() != MoveCastling {
+			continue
+		}
+
+		// Check the alternative castling notation.
+		kingTo, rookFrom, _ := p.castlingSquares(m)
+		to := kingTo
+		if !p.Chess960 {
+			to = rookFrom
+		}
+		if Square2String[m.From()]+Square2String[to] == uci {
+			alt = m
+		}
+	}
+
+	if alt != 0 {
+		return alt, nil
+	}
+	return 0, fmt.Errorf("illegal move %q", uci)
+}
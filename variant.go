@@ -0,0 +1,255 @@
+/*
+variant.go implements the pluggable rules of the game.  A [Game] consults its
+[Variant] to set up the starting position, generate and perform moves, detect
+the end of the game and read and write the variant-specific notation.
+
+The built-in variants delegate to the move generator, which dispatches on the
+[VariantKind] stored in the position.  House variants can embed one of them and
+override only the rules which differ:
+
+	// Chess with the pieces shifted one rank forward.
+	type Advanced struct{ chego.Standard }
+
+	func (Advanced) Name() string { return "advanced" }
+
+	func (Advanced) StartFEN() string {
+		return "8/rnbqkbnr/pppppppp/8/8/PPPPPPPP/RNBQKBNR/8 w - - 0 1"
+	}
+
+	g := chego.NewGame(Advanced{})
+*/
+
+package chego
+
+import "fmt"
+
+// Variant defines the rules of the game.
+type Variant interface {
+	// Name returns the name of the variant, e.g. "atomic".
+	Name() string
+	// StartFEN returns the FEN string of the starting position.
+	StartFEN() string
+	// ParseFEN parses the FEN string including the variant extensions.
+	ParseFEN(fen string) Position
+	// SerializeFEN serializes the position including the variant extensions.
+	SerializeFEN(p Position) string
+	// GenLegalMoves overwrites the move list with the legal moves.
+	GenLegalMoves(p Position, l *MoveList)
+	// MakeMove performs the legal move and applies its side effects.
+	MakeMove(p *Position, m Move)
+	// Outcome returns the result of the game and the winner, or ColorBoth
+	// for a draw.  Returns ResultUnscored if the game isn't finished yet.
+	Outcome(g *Game) (Result, Color)
+	// Move2UCI converts the move into the UCI notation.
+	Move2UCI(p Position, m Move) string
+	// UCI2Move parses the move in the UCI notation.  The list holds the
+	// legal moves of the position generated by the variant.
+	UCI2Move(p Position, l *MoveList, uci string) (Move, error)
+}
+
+/*
+Standard implements the standard chess rules, including Chess960 castling.  It
+is the default variant of the [Game].
+*/
+type Standard struct{}
+
+func (Standard) Name() string { return "standard" }
+
+func (Standard) StartFEN() string { return InitialPos }
+
+func (Standard) ParseFEN(fen string) Position {
+	return ParseVariantFEN(fen, VariantStandard)
+}
+
+func (Standard) SerializeFEN(p Position) string { return SerializeFEN(p) }
+
+func (Standard) GenLegalMoves(p Position, l *MoveList) { GenLegalMoves(p, l) }
+
+func (Standard) MakeMove(p *Position, m Move) { p.MakeMove(m) }
+
+/*
+Outcome detects checkmate, stalemate and the draws by insufficient material,
+fifty-move rule and threefold repetition.  Timeouts, resignations and draws by
+agreement are up to the caller.
+*/
+func (Standard) Outcome(g *Game) (Result, Color) {
+	switch {
+	case g.IsCheckmate():
+		return ResultCheckmate, 1 ^ g.Position.ActiveColor
+	case g.LegalMoves.Len() == 0:
+		return ResultStalemate, ColorBoth
+	case g.IsInsufficientMaterial():
+		return ResultInsufficientMaterial, ColorBoth
+	case g.IsFiftyMove():
+		return ResultFiftyMove, ColorBoth
+	case g.IsThreefoldRepetition():
+		return ResultThreefoldRepetition, ColorBoth
+	}
+	return ResultUnscored, ColorBoth
+}
+
+func (Standard) Move2UCI(_ Position, m Move) string { return Move2UCI(m) }
+
+func (Standard) UCI2Move(p Position, l *MoveList, uci string) (Move, error) {
+	return uci2Move(p, l, uci)
+}
+
+// Crazyhouse implements the Crazyhouse rules, see [VariantCrazyhouse].
+type Crazyhouse struct{ Standard }
+
+func (Crazyhouse) Name() string { return "crazyhouse" }
+
+func (Crazyhouse) StartFEN() string { return InitialCrazyhousePos }
+
+func (Crazyhouse) ParseFEN(fen string) Position {
+	return ParseVariantFEN(fen, VariantCrazyhouse)
+}
+
+// ThreeCheck implements the Three-check rules, see [VariantThreeCheck].
+type ThreeCheck struct{ Standard }
+
+func (ThreeCheck) Name() string { return "threecheck" }
+
+func (ThreeCheck) ParseFEN(fen string) Position {
+	return ParseVariantFEN(fen, VariantThreeCheck)
+}
+
+func (v ThreeCheck) Outcome(g *Game) (Result, Color) {
+	if g.IsThreeCheck() {
+		if g.Position.Checks[ColorWhite] >= 3 {
+			return ResultThreeCheck, ColorWhite
+		}
+		return ResultThreeCheck, ColorBlack
+	}
+	return v.Standard.Outcome(g)
+}
+
+// KingOfTheHill implements the King of the Hill rules.
+type KingOfTheHill struct{ Standard }
+
+func (KingOfTheHill) Name() string { return "kingofthehill" }
+
+func (KingOfTheHill) ParseFEN(fen string) Position {
+	return ParseVariantFEN(fen, VariantKingOfTheHill)
+}
+
+func (v KingOfTheHill) Outcome(g *Game) (Result, Color) {
+	if g.IsKingOfTheHill() {
+		if g.Position.Bitboards[PieceWKing]&(D4|E4|D5|E5) != 0 {
+			return ResultKingOfTheHill, ColorWhite
+		}
+		return ResultKingOfTheHill, ColorBlack
+	}
+	return v.Standard.Outcome(g)
+}
+
+// Atomic implements the Atomic rules, see [VariantAtomic].
+type Atomic struct{ Standard }
+
+func (Atomic) Name() string { return "atomic" }
+
+func (Atomic) ParseFEN(fen string) Position {
+	return ParseVariantFEN(fen, VariantAtomic)
+}
+
+func (v Atomic) Outcome(g *Game) (Result, Color) {
+	if g.IsKingExploded() {
+		if g.Position.Bitboards[PieceWKing] == 0 {
+			return ResultKingExploded, ColorBlack
+		}
+		return ResultKingExploded, ColorWhite
+	}
+	return v.Standard.Outcome(g)
+}
+
+// Antichess implements the Antichess rules, see [VariantAntichess].
+type Antichess struct{ Standard }
+
+func (Antichess) Name() string { return "antichess" }
+
+func (Antichess) StartFEN() string { return InitialAntichessPos }
+
+func (Antichess) ParseFEN(fen string) Position {
+	return ParseVariantFEN(fen, VariantAntichess)
+}
+
+func (v Antichess) Outcome(g *Game) (Result, Color) {
+	if g.IsAntichessWin() {
+		return ResultAntichessWin, g.Position.ActiveColor
+	}
+	return v.Standard.Outcome(g)
+}
+
+// Horde implements the Horde rules, see [VariantHorde].
+type Horde struct{ Standard }
+
+func (Horde) Name() string { return "horde" }
+
+func (Horde) StartFEN() string { return InitialHordePos }
+
+func (Horde) ParseFEN(fen string) Position {
+	return ParseVariantFEN(fen, VariantHorde)
+}
+
+func (v Horde) Outcome(g *Game) (Result, Color) {
+	if g.IsHordeCaptured() {
+		return ResultHordeCaptured, ColorBlack
+	}
+	return v.Standard.Outcome(g)
+}
+
+// RacingKings implements the Racing Kings rules, see [VariantRacingKings].
+type RacingKings struct{ Standard }
+
+func (RacingKings) Name() string { return "racingkings" }
+
+func (RacingKings) StartFEN() string { return InitialRacingKingsPos }
+
+func (RacingKings) ParseFEN(fen string) Position {
+	return ParseVariantFEN(fen, VariantRacingKings)
+}
+
+func (v RacingKings) Outcome(g *Game) (Result, Color) {
+	if g.IsRaceFinished() {
+		white := g.Position.Bitboards[PieceWKing]&RANK_8 != 0
+		black := g.Position.Bitboards[PieceBKing]&RANK_8 != 0
+		switch {
+		case white && black:
+			return ResultRaceFinished, ColorBoth
+		case white:
+			return ResultRaceFinished, ColorWhite
+		}
+		return ResultRaceFinished, ColorBlack
+	}
+	return v.Standard.Outcome(g)
+}
+
+/*
+optionalVariant returns the first of the optionally specified variants, or
+[Standard] if none is specified.
+*/
+func optionalVariant(variant []Variant) Variant {
+	if len(variant) > 0 {
+		return variant[0]
+	}
+	return Standard{}
+}
+
+// builtinVariants lists all built-in variants indexed by their kinds.
+var builtinVariants = [...]Variant{
+	Standard{}, Crazyhouse{}, ThreeCheck{}, KingOfTheHill{}, Atomic{},
+	Antichess{}, Horde{}, RacingKings{},
+}
+
+/*
+ParseVariant returns the built-in variant with the specified name, e.g.
+"atomic".  Returns an error if there is no such variant.
+*/
+func ParseVariant(name string) (Variant, error) {
+	for _, v := range builtinVariants {
+		if v.Name() == name {
+			return v, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown variant %q", name)
+}
@@ -0,0 +1,170 @@
+package chego
+
+import "testing"
+
+// noCastling is a house variant which starts from the position with the
+// rooks and knights swapped and forbids castling.
+type noCastling struct{ Standard }
+
+func (noCastling) Name() string { return "nocastling" }
+
+func (noCastling) StartFEN() string {
+	return "nrbqkbrn/pppppppp/8/8/8/8/PPPPPPPP/NRBQKBRN w - - 0 1"
+}
+
+func (noCastling) MakeMove(p *Position, m Move) {
+	p.MakeMove(m)
+	p.CastlingRights = 0
+}
+
+func TestHouseVariant(t *testing.T) {
+	g := NewGame(noCastling{})
+	if got := SerializeFEN(g.Position); got != g.Variant.StartFEN() {
+		t.Fatalf("expected %s got %s", g.Variant.StartFEN(), got)
+	}
+
+	g.Position.CastlingRights = CastlingWhiteShort
+	g.PushMove(NewMove(SA3, SA2, MoveNormal))
+	if g.Position.CastlingRights != 0 {
+		t.Fatalf("expected no castling rights got %d", g.Position.CastlingRights)
+	}
+
+	g.PushMove(NewMove(SA6, SA7, MoveNormal))
+	g.PopMove()
+	expected := "nrbqkbrn/pppppppp/8/8/8/P7/1PPPPPPP/NRBQKBRN b - - 0 1"
+	if got := SerializeFEN(g.Position); got != expected {
+		t.Fatalf("expected %s got %s", expected, got)
+	}
+}
+
+// frozenKings is a house variant in which the kings can't move.
+type frozenKings struct{ Standard }
+
+func (frozenKings) Name() string { return "frozenkings" }
+
+func (frozenKings) GenLegalMoves(p Position, l *MoveList) {
+	GenLegalMoves(p, l)
+
+	kings := p.Bitboards[PieceWKing] | p.Bitboards[PieceBKing]
+	n := byte(0)
+	for _, m := range l.Moves[:l.LastMoveIndex] {
+		if kings&(1<<m.From()) == 0 {
+			l.Moves[n] = m
+			n++
+		}
+	}
+	l.LastMoveIndex = n
+}
+
+func TestHouseVariantMoveGen(t *testing.T) {
+	const fen = "4k3/8/8/8/8/8/8/4K2R w - - 0 1"
+	p := ParseFEN(fen)
+	v := frozenKings{}
+
+	if got := Perft(p, 1); got != 14 {
+		t.Fatalf("expected 14 standard moves got %d", got)
+	}
+	if got := Perft(p, 1, v); got != 9 {
+		t.Fatalf("expected 9 moves got %d", got)
+	}
+	if s := PerftVerbose(p, 1, v); s.Nodes != 9 || s.Checkmates != 1 {
+		t.Fatalf("expected 9 nodes and 1 checkmate got %+v", s)
+	}
+
+	// The black king can't escape the check.
+	m := NewMove(SH8, SH1, MoveNormal)
+	if got := Move2SAN(p, m, v); got != "Rh8#" {
+		t.Fatalf("expected Rh8# got %s", got)
+	}
+	if got := Move2SAN(p, m); got != "Rh8+" {
+		t.Fatalf("expected Rh8+ got %s", got)
+	}
+
+	if _, err := replayGame(v, fen, false, []string{"e1e2"}); err == nil {
+		t.Fatalf("expected error for the king move")
+	}
+	if _, err := replayGame(v, fen, false, []string{"h1h7"}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestOutcome(t *testing.T) {
+	testcases := []struct {
+		name    string
+		variant Variant
+		fen     string
+		result  Result
+		winner  Color
+	}{
+		{"unscored", Standard{}, InitialPos, ResultUnscored, ColorBoth},
+		{
+			"checkmate", Standard{}, "7k/6Q1/6K1/8/8/8/8/8 b - - 0 1",
+			ResultCheckmate, ColorWhite,
+		},
+		{
+			"stalemate", Standard{}, "7k/8/6QK/8/8/8/8/8 b - - 0 1",
+			ResultStalemate, ColorBoth,
+		},
+		{
+			"insufficient material", Standard{}, "4k3/8/8/8/8/8/8/4K3 w - - 0 1",
+			ResultInsufficientMaterial, ColorBoth,
+		},
+		{
+			"fifty move", Standard{}, "4k3/8/8/8/8/8/8/R3K3 w - - 100 80",
+			ResultFiftyMove, ColorBoth,
+		},
+		{
+			"three check", ThreeCheck{}, "4k3/8/8/8/8/8/8/4K3 w - - 0 1 +0+3",
+			ResultThreeCheck, ColorBlack,
+		},
+		{
+			"three check knight", ThreeCheck{},
+			"k7/8/8/8/8/8/8/N6K w - - 0 1 +0+0", ResultUnscored, ColorBoth,
+		},
+		{
+			"three check bare kings", ThreeCheck{},
+			"k7/8/8/8/8/8/8/7K w - - 0 1 +0+0", ResultInsufficientMaterial,
+			ColorBoth,
+		},
+		{
+			"king of the hill", KingOfTheHill{}, "8/8/8/3k4/8/8/8/4K3 w - - 0 1",
+			ResultKingOfTheHill, ColorBlack,
+		},
+		{
+			"king exploded", Atomic{}, "8/8/8/8/8/8/8/4K3 b - - 0 1",
+			ResultKingExploded, ColorWhite,
+		},
+		{
+			"antichess", Antichess{}, "8/8/8/8/8/8/8/4K3 b - - 0 1",
+			ResultAntichessWin, ColorBlack,
+		},
+		{
+			"horde captured", Horde{}, "4k3/8/8/8/8/8/8/8 w - - 0 1",
+			ResultHordeCaptured, ColorBlack,
+		},
+		{
+			"race drawn", RacingKings{}, "K6k/8/8/8/8/8/8/8 w - - 0 1",
+			ResultRaceFinished, ColorBoth,
+		},
+		{
+			"race open", RacingKings{}, "8/8/8/8/8/8/k6K/8 w - - 0 1",
+			ResultUnscored, ColorBoth,
+		},
+		{
+			"horde knight", Horde{}, "k7/8/8/8/8/8/8/N7 b - - 0 1",
+			ResultUnscored, ColorBoth,
+		},
+	}
+
+	for _, tc := range testcases {
+		g := NewGame(tc.variant)
+		g.Position = tc.variant.ParseFEN(tc.fen)
+		tc.variant.GenLegalMoves(g.Position, &g.LegalMoves)
+
+		result, winner := g.Outcome()
+		if result != tc.result || winner != tc.winner {
+			t.Fatalf("%s: expected %d %d got %d %d", tc.name, tc.result,
+				tc.winner, result, winner)
+		}
+	}
+}
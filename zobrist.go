@@ -17,8 +17,17 @@ var (
 	// Used only when black is the active color.
 	epKeys       [64]uint64
 	castlingKeys [16]uint64
+	// Used only in Crazyhouse.  Each pocket may contain at most 16 pieces
+	// of the same type.
+	pocketKeys   [10][17]uint64
+	promotedKeys [64]uint64
+	// Used only in Three-check.
+	checkKeys [2][4]uint64
 	// Used only when black is the active color.
 	colorKey uint64
+	// Used only in the variants, so the same piece placement has different
+	// keys in different variants.  Zero for the standard chess.
+	variantKeys [VariantRacingKings + 1]uint64
 )
 
 /*
@@ -26,31 +35,60 @@ InitZobristKeys initializes the pseudo-random keys used in the Zobrist hashing
 scheme.  Call this function ONCE as close as possible to the start of your
 program.
 
+The keys are generated with a fixed seed, so they are identical across the
+program runs and the stored keys (see [Snapshot]) remain valid after restart.
+
 NOTE: Threefold repetitions will not be detected if this funtcion wasn't called.
 */
 func InitZobristKeys() {
+	r := rand.New(rand.NewPCG(0x636865676f, 0x7a6f6272697374))
+
 	for i := PieceWPawn; i <= PieceBKing; i++ {
 		for square := range 64 {
-			pieceKeys[i][square] = rand.Uint64()
+			pieceKeys[i][square] = r.Uint64()
 		}
 	}
 
 	for square := range 64 {
-		epKeys[square] = rand.Uint64()
+		epKeys[square] = r.Uint64()
 	}
 
 	for i := range 16 {
-		castlingKeys[i] = rand.Uint64()
+		castlingKeys[i] = r.Uint64()
+	}
+
+	for i := range 10 {
+		for cnt := range 17 {
+			pocketKeys[i][cnt] = r.Uint64()
+		}
+	}
+
+	for square := range 64 {
+		promotedKeys[square] = r.Uint64()
+	}
+
+	for c := range 2 {
+		for cnt := range 4 {
+			checkKeys[c][cnt] = r.Uint64()
+		}
 	}
 
-	colorKey = rand.Uint64()
+	colorKey = r.Uint64()
+
+	// Generated last to keep the keys of the standard positions unchanged.
+	for v := VariantCrazyhouse; v < len(variantKeys); v++ {
+		variantKeys[v] = r.Uint64()
+	}
 }
 
 /*
-zobristKey hashes the given position into a 64-bit unsigned integer.  This
+ZobristKey hashes the given position into a 64-bit unsigned integer.  This
 allows positions to be used as lookup keys and stored or compared efficiently.
+The same piece placement has different keys in different variants.
+
+NOTE: All positions will have the same key if [InitZobristKeys] wasn't called.
 */
-func zobristKey(p Position) (key uint64) {
+func ZobristKey(p Position) (key uint64) {
 	for i := PieceWPawn; i <= PieceBKing; i++ {
 		for p.Bitboards[i] > 0 {
 			key ^= pieceKeys[i][popLSB(&p.Bitboards[i])]
@@ -61,7 +99,25 @@ func zobristKey(p Position) (key uint64) {
 
 	key ^= castlingKeys[p.CastlingRights]
 
-	key ^= colorKey & uint64(p.ActiveColor)
+	if p.ActiveColor == ColorBlack {
+		key ^= colorKey
+	}
+
+	key ^= variantKeys[p.Variant]
+
+	if p.Variant == VariantCrazyhouse {
+		for i, cnt := range p.Pockets {
+			key ^= pocketKeys[i][cnt]
+		}
+		for p.Promoted > 0 {
+			key ^= promotedKeys[popLSB(&p.Promoted)]
+		}
+	}
+
+	if p.Variant == VariantThreeCheck {
+		key ^= checkKeys[ColorWhite][min(p.Checks[ColorWhite], 3)]
+		key ^= checkKeys[ColorBlack][min(p.Checks[ColorBlack], 3)]
+	}
 
 	return key
 }
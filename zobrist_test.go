@@ -0,0 +1,69 @@
+package chego
+
+import "testing"
+
+func TestZobristKey(t *testing.T) {
+	testcases := []struct {
+		name string
+		a, b string
+	}{
+		{
+			"active color",
+			"4k3/8/8/8/8/8/8/4K3 w - - 0 1",
+			"4k3/8/8/8/8/8/8/4K3 b - - 0 1",
+		},
+		{
+			"castling rights",
+			"r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1",
+			"r3k2r/8/8/8/8/8/8/R3K2R w Kkq - 0 1",
+		},
+		{
+			"piece placement",
+			"4k3/8/8/8/8/8/8/4K3 w - - 0 1",
+			"4k3/8/8/8/8/8/8/3K4 w - - 0 1",
+		},
+		{
+			"pockets",
+			"4k3/8/8/8/8/8/8/4K3[N] w - - 0 1",
+			"4k3/8/8/8/8/8/8/4K3[n] w - - 0 1",
+		},
+		{
+			"promoted pieces",
+			"4k3/8/8/8/8/8/8/Q3K3[] w - - 0 1",
+			"4k3/8/8/8/8/8/8/Q~3K3[] w - - 0 1",
+		},
+		{
+			"checks",
+			"4k3/8/8/8/8/8/8/4K3 w - - 0 1 +1+0",
+			"4k3/8/8/8/8/8/8/4K3 w - - 0 1 +0+1",
+		},
+	}
+
+	for _, tc := range testcases {
+		if ZobristKey(ParseFEN(tc.a)) == ZobristKey(ParseFEN(tc.b)) {
+			t.Fatalf("test \"%s\" failed: keys are equal", tc.name)
+		}
+	}
+
+	// The same placement in different variants.
+	keys := make(map[uint64]VariantKind)
+	for v := VariantStandard; v <= VariantRacingKings; v++ {
+		key := ZobristKey(ParseVariantFEN(InitialPos, v))
+		if prev, ok := keys[key]; ok {
+			t.Fatalf("variants %d and %d have equal keys", prev, v)
+		}
+		keys[key] = v
+	}
+	if ZobristKey(ParseVariantFEN(InitialPos, VariantStandard)) !=
+		ZobristKey(ParseFEN(InitialPos)) {
+		t.Fatalf("expected the standard key to be unchanged")
+	}
+}
+
+func BenchmarkZobristKey(b *testing.B) {
+	pos := ParseFEN("r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1")
+
+	for b.Loop() {
+		ZobristKey(pos)
+	}
+}